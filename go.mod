@@ -7,6 +7,7 @@ require (
 	github.com/go-sql-driver/mysql v1.4.1
 	github.com/go-xorm/xorm v0.7.1
 	github.com/golang/protobuf v1.2.0
+	github.com/mattn/go-sqlite3 v1.10.0
 	github.com/natefinch/lumberjack v2.0.0+incompatible
 	github.com/sony/sonyflake v0.0.0-20181109022403-6d5bd6181009
 	go.uber.org/atomic v1.3.2 // indirect
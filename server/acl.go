@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dato-live/golazy/server/config"
+	"github.com/dato-live/golazy/server/store/types"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// ACL 路由访问控制规则：哪些客户端可以向哪些目标发送哪些命令
+type ACL struct {
+	rules        []config.ACLRule
+	defaultAllow bool
+}
+
+// 模式中匹配任意字符的通配符
+const aclWildcard = "*"
+
+// NewACL builds the routing access control from the configuration.
+func NewACL(conf config.ACLConfig) *ACL {
+	return &ACL{
+		rules:        conf.Rules,
+		defaultAllow: conf.Default != types.ACLDeny,
+	}
+}
+
+// Allowed checks whether the client 'from' may send the command to the target 'to'. The request is
+// allowed if one of the rules matching the sender and the target allows the command; if no rule
+// matches them, the default applies.
+func (acl *ACL) Allowed(from string, to string, commandID int64) bool {
+	matched := false
+	for _, rule := range acl.rules {
+		if !aclMatch(rule.From, from) || !aclMatch(rule.To, to) {
+			continue
+		}
+		matched = true
+		if len(rule.Commands) == 0 {
+			return true
+		}
+		for _, id := range rule.Commands {
+			if id == commandID {
+				return true
+			}
+		}
+	}
+	return !matched && acl.defaultAllow
+}
+
+// checkACL checks the request sent to 'to' against the routing access control, and writes an audit log
+// entry if it is denied. 'to' is the target of the request, or the ClientID of a client it is resolved to
+// (a member of the service or a target of the broadcast). 'source' describes where the request comes from.
+func checkACL(req *DMClientReq, to string, source string) bool {
+	if globals.acl.Allowed(req.From, to, req.CommandID) {
+		return true
+	}
+	logger.Warn(fmt.Sprintf("[Audit] [Access Denied] Client [%s] is not allowed to send command [%d] to [%s]", req.From, req.CommandID, to),
+		zap.String("From", req.From), zap.String("To", to), zap.Int64("CommandID", req.CommandID), zap.String("ReqID", req.ReqID), zap.String("source", source))
+	return false
+}
+
+// aclMatch checks if s matches the pattern, in which '*' matches any sequence of characters.
+func aclMatch(pattern string, s string) bool {
+	parts := strings.Split(pattern, aclWildcard)
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := len(parts) - 1
+	for _, part := range parts[1:last] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return len(s) >= len(parts[last]) && strings.HasSuffix(s, parts[last])
+}
@@ -26,15 +26,30 @@ type Adapter interface {
 
 	GetReqByMsgID(msgId string) (*types.ReqReceived, error)
 	GetRespByMsgID(msgId string) (*types.RespReceived, error)
+	//获取from发送给to的请求reqId，存在多条时返回最后存储的一条
+	GetReqByReqID(from string, to string, reqId string) (*types.ReqReceived, error)
 
 	UpdateReq(req *types.ReqReceived) error
 	UpdateResp(resp *types.RespReceived) error
+	//仅当记录当前状态为status时更新，返回是否已更新，用于并发更新同一消息状态时不覆盖其他更新
+	UpdateReqIf(req *types.ReqReceived, status string) (bool, error)
+	UpdateRespIf(resp *types.RespReceived, status string) (bool, error)
 
 	DeleteReq(id int64) error
 	DeleteResp(id int64) error
 
-	DeleteSendedOrExpireMsg() error
+	//删除超过保留时间的消息，返回删除的请求及响应数量
+	DeleteSendedOrExpireMsg(policy types.RetentionPolicy) (int64, int64, error)
 
+	//获取已到重传时间的发送失败(Failed)及超时未确认(Sent)消息
 	GetRetryReq() ([]types.ReqReceived, error)
 	GetRetryResp() ([]types.RespReceived, error)
+
+	//获取发送给指定客户端且未过期的待发送(Pending)、发送失败(Failed)及未确认(Sent)消息，按存储顺序排列
+	GetPendingReq(to string) ([]types.ReqReceived, error)
+	GetPendingResp(to string) ([]types.RespReceived, error)
+
+	//获取符合条件的死信消息，按存储顺序排列
+	GetDeadReq(filter types.DeadLetterFilter) ([]types.ReqReceived, error)
+	GetDeadResp(filter types.DeadLetterFilter) ([]types.RespReceived, error)
 }
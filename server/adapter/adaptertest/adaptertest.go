@@ -0,0 +1,394 @@
+//数据库适配器公共测试用例包，各适配器的测试以各自的Harness运行同一组用例
+package adaptertest
+
+import (
+	"github.com/dato-live/golazy/server/adapter"
+	t "github.com/dato-live/golazy/server/store/types"
+	"testing"
+	"time"
+)
+
+// MaxRetryCount is the max_retry_count the adapters under test must be opened with.
+const MaxRetryCount = 3
+
+// Harness gives the suite access to one adapter implementation.
+type Harness struct {
+	// Open returns a new adapter on an empty database, opened with MaxRetryCount, and the function closing it.
+	Open func(tb testing.TB) (adapter.Adapter, func())
+	// SetAdded changes the time the stored request or response msg was received at, which the adapter sets on insert.
+	// msg is a *types.ReqReceived or a *types.RespReceived.
+	SetAdded func(tb testing.TB, a adapter.Adapter, msg interface{}, added time.Time)
+}
+
+// Run runs the behaviour shared by all adapters as subtests of tt.
+func Run(tt *testing.T, h Harness) {
+	cases := []struct {
+		name string
+		test func(tt *testing.T, h Harness, a adapter.Adapter)
+	}{
+		{"CheckDbVersion", testCheckDbVersion},
+		{"InsertUpdateReq", testInsertUpdateReq},
+		{"UpdateReqIf", testUpdateReqIf},
+		{"InsertUpdateResp", testInsertUpdateResp},
+		{"GetReqByReqID", testGetReqByReqID},
+		{"GetRetryReq", testGetRetryReq},
+		{"GetPendingReq", testGetPendingReq},
+		{"GetDeadReq", testGetDeadReq},
+		{"DeleteSendedOrExpireMsg", testDeleteSendedOrExpireMsg},
+		{"DeleteSendedOrExpireMsgBatches", testDeleteSendedOrExpireMsgBatches},
+	}
+	for _, c := range cases {
+		c := c
+		tt.Run(c.name, func(tt *testing.T) {
+			a, done := h.Open(tt)
+			defer done()
+			c.test(tt, h, a)
+		})
+	}
+}
+
+// insertReq stores a request with the given status, received at 'added'.
+func insertReq(tb testing.TB, h Harness, a adapter.Adapter, req t.ReqReceived, added time.Time) *t.ReqReceived {
+	if req.ExpiresAt.IsZero() {
+		req.ExpiresAt = t.TimeNow().Add(time.Hour)
+	}
+	if err := a.InsertReq(&req); err != nil {
+		tb.Fatal(err)
+	}
+	h.SetAdded(tb, a, &req, added)
+	return &req
+}
+
+// insertResp stores a response with the given status, received at 'added'.
+func insertResp(tb testing.TB, h Harness, a adapter.Adapter, resp t.RespReceived, added time.Time) *t.RespReceived {
+	if resp.ExpiresAt.IsZero() {
+		resp.ExpiresAt = t.TimeNow().Add(time.Hour)
+	}
+	if err := a.InsertResp(&resp); err != nil {
+		tb.Fatal(err)
+	}
+	h.SetAdded(tb, a, &resp, added)
+	return &resp
+}
+
+func reqMsgIDs(items []t.ReqReceived) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.MsgID)
+	}
+	return ids
+}
+
+func respMsgIDs(items []t.RespReceived) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.MsgID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testCheckDbVersion(tt *testing.T, h Harness, a adapter.Adapter) {
+	if err := a.CheckDbVersion(); err != nil {
+		tt.Fatalf("CheckDbVersion: %v", err)
+	}
+}
+
+func testInsertUpdateReq(tt *testing.T, h Harness, a adapter.Adapter) {
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "m1", ReqID: "r1", From: "a", To: "b", Status: t.StatusQueued}, t.TimeNow())
+
+	req, err := a.GetReqByMsgID("m1")
+	if err != nil {
+		tt.Fatalf("GetReqByMsgID: %v", err)
+	}
+	if req.ReqID != "r1" || req.From != "a" || req.To != "b" || req.Status != t.StatusQueued {
+		tt.Fatalf("GetReqByMsgID returned %+v", req)
+	}
+
+	req.Status, req.Retries, req.LastError = t.StatusFailed, 2, "send failed"
+	if err := a.UpdateReq(req); err != nil {
+		tt.Fatalf("UpdateReq: %v", err)
+	}
+	if got, _ := a.GetReqByMsgID("m1"); got.Status != t.StatusFailed || got.Retries != 2 || got.LastError != "send failed" {
+		tt.Fatalf("after UpdateReq got %+v", got)
+	}
+	//重新入队时重试次数须能清零
+	req.Status, req.Retries, req.LastError = t.StatusAcked, 0, ""
+	if err := a.UpdateReq(req); err != nil {
+		tt.Fatalf("UpdateReq: %v", err)
+	}
+	if got, _ := a.GetReqByMsgID("m1"); got.Status != t.StatusAcked || got.Retries != 0 || got.LastError != "" {
+		tt.Fatalf("after UpdateReq got %+v", got)
+	}
+
+	if _, err := a.GetReqByMsgID("missing"); err == nil {
+		tt.Fatal("GetReqByMsgID of a missing message returned no error")
+	}
+
+	if err := a.DeleteReq(req.Id); err != nil {
+		tt.Fatalf("DeleteReq: %v", err)
+	}
+	if _, err := a.GetReqByMsgID("m1"); err == nil {
+		tt.Fatal("request still stored after DeleteReq")
+	}
+}
+
+func testUpdateReqIf(tt *testing.T, h Harness, a adapter.Adapter) {
+	req := insertReq(tt, h, a, t.ReqReceived{MsgID: "m1", ReqID: "r1", From: "a", To: "b", Status: t.StatusSent}, t.TimeNow())
+
+	req.Status = t.StatusAcked
+	if ok, err := a.UpdateReqIf(req, t.StatusSent); !ok || err != nil {
+		tt.Fatalf("UpdateReqIf of the current status returned %v, %v", ok, err)
+	}
+	//状态已被其他更新修改时不覆盖
+	stale := *req
+	stale.Status = t.StatusFailed
+	if ok, err := a.UpdateReqIf(&stale, t.StatusSent); ok || err != nil {
+		tt.Fatalf("UpdateReqIf of a stale status returned %v, %v", ok, err)
+	}
+	if got, _ := a.GetReqByMsgID("m1"); got.Status != t.StatusAcked {
+		tt.Fatalf("stale update overwrote the status: %s", got.Status)
+	}
+	//状态不变时也视为更新成功
+	if ok, err := a.UpdateReqIf(req, t.StatusAcked); !ok || err != nil {
+		tt.Fatalf("UpdateReqIf without change returned %v, %v", ok, err)
+	}
+
+	resp := insertResp(tt, h, a, t.RespReceived{MsgID: "p1", RespID: "r1", From: "b", To: "a", Status: t.StatusSent}, t.TimeNow())
+	resp.Status = t.StatusAcked
+	if ok, err := a.UpdateRespIf(resp, t.StatusQueued); ok || err != nil {
+		tt.Fatalf("UpdateRespIf of a stale status returned %v, %v", ok, err)
+	}
+	if ok, err := a.UpdateRespIf(resp, t.StatusSent); !ok || err != nil {
+		tt.Fatalf("UpdateRespIf of the current status returned %v, %v", ok, err)
+	}
+}
+
+func testInsertUpdateResp(tt *testing.T, h Harness, a adapter.Adapter) {
+	insertResp(tt, h, a, t.RespReceived{MsgID: "m1", RespID: "r1", From: "b", To: "a", Status: t.StatusPending, ReqMsgID: "q1", LatencyMs: 12}, t.TimeNow())
+
+	resp, err := a.GetRespByMsgID("m1")
+	if err != nil {
+		tt.Fatalf("GetRespByMsgID: %v", err)
+	}
+	if resp.RespID != "r1" || resp.ReqMsgID != "q1" || resp.LatencyMs != 12 {
+		tt.Fatalf("GetRespByMsgID returned %+v", resp)
+	}
+	resp.Status = t.StatusSent
+	if err := a.UpdateResp(resp); err != nil {
+		tt.Fatalf("UpdateResp: %v", err)
+	}
+	if got, _ := a.GetRespByMsgID("m1"); got.Status != t.StatusSent {
+		tt.Fatalf("after UpdateResp got status %s", got.Status)
+	}
+	if err := a.DeleteResp(resp.Id); err != nil {
+		tt.Fatalf("DeleteResp: %v", err)
+	}
+	if _, err := a.GetRespByMsgID("m1"); err == nil {
+		tt.Fatal("response still stored after DeleteResp")
+	}
+}
+
+func testGetReqByReqID(tt *testing.T, h Harness, a adapter.Adapter) {
+	now := t.TimeNow()
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "m1", ReqID: "r1", From: "a", To: "b", Status: t.StatusFailed}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "m2", ReqID: "r1", From: "a", To: "b", Status: t.StatusQueued}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "m3", ReqID: "r1", From: "c", To: "b", Status: t.StatusQueued}, now)
+
+	req, err := a.GetReqByReqID("a", "b", "r1")
+	if err != nil {
+		tt.Fatalf("GetReqByReqID: %v", err)
+	}
+	if req.MsgID != "m2" {
+		tt.Fatalf("GetReqByReqID returned %s, expected the last stored m2", req.MsgID)
+	}
+	if _, err := a.GetReqByReqID("a", "c", "r1"); err == nil {
+		tt.Fatal("GetReqByReqID matched a request sent to another client")
+	}
+}
+
+func testGetRetryReq(tt *testing.T, h Harness, a adapter.Adapter) {
+	now := t.TimeNow()
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "due", Status: t.StatusFailed, NextAttemptAt: now.Add(-time.Minute)}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "later", Status: t.StatusFailed, NextAttemptAt: now.Add(time.Hour)}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "unacked", Status: t.StatusSent}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "exhausted", Status: t.StatusFailed, Retries: MaxRetryCount + 1}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "expired", Status: t.StatusFailed, ExpiresAt: now.Add(-time.Hour)}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "pending", Status: t.StatusPending}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "dead", Status: t.StatusDead}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "failed", Status: t.StatusFailed}, now)
+
+	//按接收顺序返回
+	items, err := a.GetRetryReq()
+	if err != nil {
+		tt.Fatalf("GetRetryReq: %v", err)
+	}
+	if got, want := reqMsgIDs(items), []string{"due", "unacked", "failed"}; !equalIDs(got, want) {
+		tt.Fatalf("GetRetryReq returned %v, expected %v", got, want)
+	}
+
+	insertResp(tt, h, a, t.RespReceived{MsgID: "due", Status: t.StatusFailed, NextAttemptAt: now.Add(-time.Minute)}, now)
+	insertResp(tt, h, a, t.RespReceived{MsgID: "later", Status: t.StatusFailed, NextAttemptAt: now.Add(time.Hour)}, now)
+	insertResp(tt, h, a, t.RespReceived{MsgID: "unacked", Status: t.StatusSent}, now)
+	resps, err := a.GetRetryResp()
+	if err != nil {
+		tt.Fatalf("GetRetryResp: %v", err)
+	}
+	if got, want := respMsgIDs(resps), []string{"due", "unacked"}; !equalIDs(got, want) {
+		tt.Fatalf("GetRetryResp returned %v, expected %v", got, want)
+	}
+}
+
+func testGetPendingReq(tt *testing.T, h Harness, a adapter.Adapter) {
+	now := t.TimeNow()
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "p1", To: "b", Status: t.StatusPending}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "other", To: "c", Status: t.StatusPending}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "f1", To: "b", Status: t.StatusFailed}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "acked", To: "b", Status: t.StatusAcked}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "expired", To: "b", Status: t.StatusPending, ExpiresAt: now.Add(-time.Minute)}, now)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "s1", To: "b", Status: t.StatusSent}, now)
+
+	items, err := a.GetPendingReq("b")
+	if err != nil {
+		tt.Fatalf("GetPendingReq: %v", err)
+	}
+	if got, want := reqMsgIDs(items), []string{"p1", "f1", "s1"}; !equalIDs(got, want) {
+		tt.Fatalf("GetPendingReq returned %v, expected %v", got, want)
+	}
+
+	insertResp(tt, h, a, t.RespReceived{MsgID: "r1", To: "b", Status: t.StatusPending}, now)
+	insertResp(tt, h, a, t.RespReceived{MsgID: "r2", To: "b", Status: t.StatusDead}, now)
+	resps, err := a.GetPendingResp("b")
+	if err != nil {
+		tt.Fatalf("GetPendingResp: %v", err)
+	}
+	if got, want := respMsgIDs(resps), []string{"r1"}; !equalIDs(got, want) {
+		tt.Fatalf("GetPendingResp returned %v, expected %v", got, want)
+	}
+}
+
+func testGetDeadReq(tt *testing.T, h Harness, a adapter.Adapter) {
+	now := t.TimeNow()
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "d1", ReqID: "r1", From: "a", To: "b", Status: t.StatusDead}, now.Add(-3*time.Hour))
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "d2", ReqID: "r2", From: "b", To: "c", Status: t.StatusDead}, now.Add(-2*time.Hour))
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "d3", ReqID: "r3", From: "c", To: "d", Status: t.StatusDead}, now.Add(-time.Hour))
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "f1", ReqID: "r1", From: "a", To: "b", Status: t.StatusFailed}, now)
+
+	cases := []struct {
+		name   string
+		filter t.DeadLetterFilter
+		want   []string
+	}{
+		{"all", t.DeadLetterFilter{}, []string{"d1", "d2", "d3"}},
+		{"msg id", t.DeadLetterFilter{MsgID: "d2"}, []string{"d2"}},
+		{"client as sender or receiver", t.DeadLetterFilter{ClientID: "b"}, []string{"d1", "d2"}},
+		{"req id", t.DeadLetterFilter{ReqID: "r1"}, []string{"d1"}},
+		{"since", t.DeadLetterFilter{Since: now.Add(-150 * time.Minute)}, []string{"d2", "d3"}},
+		{"until", t.DeadLetterFilter{Until: now.Add(-150 * time.Minute)}, []string{"d1"}},
+		{"limit", t.DeadLetterFilter{Limit: 2}, []string{"d1", "d2"}},
+		{"no match", t.DeadLetterFilter{ClientID: "b", ReqID: "r3"}, []string{}},
+	}
+	for _, c := range cases {
+		items, err := a.GetDeadReq(c.filter)
+		if err != nil {
+			tt.Fatalf("%s: GetDeadReq: %v", c.name, err)
+		}
+		if got := reqMsgIDs(items); !equalIDs(got, c.want) {
+			tt.Errorf("%s: GetDeadReq returned %v, expected %v", c.name, got, c.want)
+		}
+	}
+
+	insertResp(tt, h, a, t.RespReceived{MsgID: "d4", RespID: "r1", From: "b", To: "a", Status: t.StatusDead}, now)
+	resps, err := a.GetDeadResp(t.DeadLetterFilter{ReqID: "r1"})
+	if err != nil {
+		tt.Fatalf("GetDeadResp: %v", err)
+	}
+	if got, want := respMsgIDs(resps), []string{"d4"}; !equalIDs(got, want) {
+		tt.Fatalf("GetDeadResp returned %v, expected %v", got, want)
+	}
+}
+
+func testDeleteSendedOrExpireMsg(tt *testing.T, h Harness, a adapter.Adapter) {
+	now := t.TimeNow()
+	old, recent := now.Add(-3*time.Hour), now.Add(-30*time.Minute)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "responded-old", Status: t.StatusResponded}, old)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "responded-recent", Status: t.StatusResponded}, recent)
+	//已送达但未响应的请求保留至过期
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "acked-old", Status: t.StatusAcked}, old)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "acked-expired", Status: t.StatusAcked, ExpiresAt: old}, old)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "timeout-old", Status: t.StatusTimeout}, old)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "timeout-recent", Status: t.StatusTimeout}, recent)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "expired-old", Status: t.StatusFailed, ExpiresAt: old}, old)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "expired-recent", Status: t.StatusPending, ExpiresAt: recent}, old)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "pending", Status: t.StatusPending}, old)
+	insertReq(tt, h, a, t.ReqReceived{MsgID: "dead-old", Status: t.StatusDead, ExpiresAt: old}, old)
+	insertResp(tt, h, a, t.RespReceived{MsgID: "resp-acked-old", Status: t.StatusAcked}, old)
+	insertResp(tt, h, a, t.RespReceived{MsgID: "resp-pending", Status: t.StatusPending}, old)
+
+	policy := t.RetentionPolicy{Succeeded: time.Hour, Failed: time.Hour, BatchSize: 100}
+	reqCount, respCount, err := a.DeleteSendedOrExpireMsg(policy)
+	if err != nil {
+		tt.Fatalf("DeleteSendedOrExpireMsg: %v", err)
+	}
+	if reqCount != 4 || respCount != 1 {
+		tt.Fatalf("DeleteSendedOrExpireMsg deleted %d requests and %d responses, expected 4 and 1", reqCount, respCount)
+	}
+	for _, msgID := range []string{"responded-old", "acked-expired", "timeout-old", "expired-old"} {
+		if _, err := a.GetReqByMsgID(msgID); err == nil {
+			tt.Errorf("%s was not purged", msgID)
+		}
+	}
+	//死信保留时间为0时不自动清理
+	for _, msgID := range []string{"responded-recent", "acked-old", "timeout-recent", "expired-recent", "pending", "dead-old"} {
+		if _, err := a.GetReqByMsgID(msgID); err != nil {
+			tt.Errorf("%s was purged", msgID)
+		}
+	}
+	if _, err := a.GetRespByMsgID("resp-acked-old"); err == nil {
+		tt.Error("resp-acked-old was not purged")
+	}
+	if _, err := a.GetRespByMsgID("resp-pending"); err != nil {
+		tt.Error("resp-pending was purged")
+	}
+
+	policy.Dead = time.Hour
+	if reqCount, _, _ = a.DeleteSendedOrExpireMsg(policy); reqCount != 1 {
+		tt.Fatalf("DeleteSendedOrExpireMsg deleted %d dead letters, expected 1", reqCount)
+	}
+	if _, err := a.GetReqByMsgID("dead-old"); err == nil {
+		tt.Error("dead-old was not purged")
+	}
+}
+
+func testDeleteSendedOrExpireMsgBatches(tt *testing.T, h Harness, a adapter.Adapter) {
+	old := t.TimeNow().Add(-3 * time.Hour)
+	for i := 0; i < 25; i++ {
+		insertReq(tt, h, a, t.ReqReceived{MsgID: "responded-" + string(rune('a'+i)), Status: t.StatusResponded}, old)
+		//分批删除时不能删除不符合条件的记录
+		insertReq(tt, h, a, t.ReqReceived{MsgID: "queued-" + string(rune('a'+i)), Status: t.StatusQueued}, old)
+	}
+
+	reqCount, _, err := a.DeleteSendedOrExpireMsg(t.RetentionPolicy{Succeeded: time.Hour, Failed: time.Hour, BatchSize: 10})
+	if err != nil {
+		tt.Fatalf("DeleteSendedOrExpireMsg: %v", err)
+	}
+	if reqCount != 25 {
+		tt.Fatalf("DeleteSendedOrExpireMsg deleted %d requests, expected 25", reqCount)
+	}
+	for i := 0; i < 25; i++ {
+		if _, err := a.GetReqByMsgID("queued-" + string(rune('a'+i))); err != nil {
+			tt.Errorf("queued-%c was purged", 'a'+i)
+		}
+	}
+}
@@ -0,0 +1,475 @@
+// 内存数据库适配器，消息仅保存在进程内存中，进程退出后数据丢失，适用于测试及临时部署
+package memory
+
+import (
+	"errors"
+	"github.com/dato-live/golazy/server/config"
+	"github.com/dato-live/golazy/server/store"
+	t "github.com/dato-live/golazy/server/store/types"
+	"sort"
+	"sync"
+	"time"
+)
+
+// adapter保存内存中的数据表
+type adapter struct {
+	lock sync.RWMutex
+
+	open    bool
+	version string
+
+	kvMeta map[string]t.KvMeta
+	reqs   map[int64]*t.ReqReceived
+	resps  map[int64]*t.RespReceived
+
+	// MsgID到主键的索引，与reqs/resps同步维护
+	reqIndex  map[string]int64
+	respIndex map[string]int64
+
+	// 模拟数据库自增主键
+	lastReqId  int64
+	lastRespId int64
+}
+
+const (
+	dbVersion   = "104"
+	adapterName = "memory"
+)
+
+var configs config.Config
+
+var errNotOpen = errors.New("memory adapter is not open")
+
+func (a *adapter) Open(conf config.Config) error {
+	configs = conf
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.open {
+		return errors.New("memory adapter is already connected")
+	}
+	//内存数据库无需初始化，打开即可使用
+	if a.kvMeta == nil {
+		a.createTables()
+	}
+	a.open = true
+	return nil
+}
+
+// Close releases all stored data.
+func (a *adapter) Close() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.open = false
+	a.version = ""
+	a.kvMeta = nil
+	a.reqs = nil
+	a.resps = nil
+	a.reqIndex = nil
+	a.respIndex = nil
+	return nil
+}
+
+// IsOpen returns true if the adapter has been opened.
+func (a *adapter) IsOpen() bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.open
+}
+
+// CheckDbVersion checks whether the actual DB version matches the expected version of this adapter.
+func (a *adapter) CheckDbVersion() error {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	vers, ok := a.kvMeta["version"]
+	if !ok {
+		return errors.New("Database not initialized")
+	}
+	if vers.KeyValue != dbVersion {
+		return errors.New("Invalid database version " + vers.KeyValue +
+			". Expected " + dbVersion)
+	}
+	return nil
+}
+
+// GetName returns string that adapter uses to register itself with store.
+func (a *adapter) GetName() string {
+	return adapterName
+}
+
+// CreateDb initializes the storage. Existing data is always discarded.
+func (a *adapter) CreateDb(reset bool) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.createTables()
+	return nil
+}
+
+// createTables must be called with the lock held.
+func (a *adapter) createTables() {
+	a.kvMeta = map[string]t.KvMeta{"version": {KeyName: "version", KeyValue: dbVersion}}
+	a.reqs = make(map[int64]*t.ReqReceived)
+	a.resps = make(map[int64]*t.RespReceived)
+	a.reqIndex = make(map[string]int64)
+	a.respIndex = make(map[string]int64)
+	a.version = dbVersion
+}
+
+func (a *adapter) InsertReq(received *t.ReqReceived) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.open {
+		return errNotOpen
+	}
+	a.lastReqId++
+	received.Id = a.lastReqId
+	received.Added = t.TimeNow()
+	item := *received
+	a.reqs[item.Id] = &item
+	a.reqIndex[item.MsgID] = item.Id
+	return nil
+}
+
+func (a *adapter) InsertResp(received *t.RespReceived) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.open {
+		return errNotOpen
+	}
+	a.lastRespId++
+	received.Id = a.lastRespId
+	received.Added = t.TimeNow()
+	item := *received
+	a.resps[item.Id] = &item
+	a.respIndex[item.MsgID] = item.Id
+	return nil
+}
+
+func (a *adapter) GetReqByMsgID(msgId string) (*t.ReqReceived, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if v, ok := a.reqs[a.reqIndex[msgId]]; ok {
+		item := *v
+		return &item, nil
+	}
+	return nil, errors.New("Record not found!")
+}
+
+func (a *adapter) GetReqByReqID(from string, to string, reqId string) (*t.ReqReceived, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	var found *t.ReqReceived
+	for _, v := range a.reqs {
+		if v.From == from && v.To == to && v.ReqID == reqId && (found == nil || v.Id > found.Id) {
+			found = v
+		}
+	}
+	if found == nil {
+		return nil, errors.New("Record not found!")
+	}
+	item := *found
+	return &item, nil
+}
+
+func (a *adapter) GetRespByMsgID(msgId string) (*t.RespReceived, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	if v, ok := a.resps[a.respIndex[msgId]]; ok {
+		item := *v
+		return &item, nil
+	}
+	return nil, errors.New("Record not found!")
+}
+
+func (a *adapter) UpdateReq(req *t.ReqReceived) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.open {
+		return errNotOpen
+	}
+	old, ok := a.reqs[req.Id]
+	if !ok {
+		return errors.New("Record not found!")
+	}
+	a.updateReq(old, req)
+	return nil
+}
+
+func (a *adapter) UpdateResp(resp *t.RespReceived) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.open {
+		return errNotOpen
+	}
+	old, ok := a.resps[resp.Id]
+	if !ok {
+		return errors.New("Record not found!")
+	}
+	a.updateResp(old, resp)
+	return nil
+}
+
+func (a *adapter) UpdateReqIf(req *t.ReqReceived, status string) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.open {
+		return false, errNotOpen
+	}
+	old, ok := a.reqs[req.Id]
+	if !ok || old.Status != status {
+		return false, nil
+	}
+	a.updateReq(old, req)
+	return true, nil
+}
+
+func (a *adapter) UpdateRespIf(resp *t.RespReceived, status string) (bool, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.open {
+		return false, errNotOpen
+	}
+	old, ok := a.resps[resp.Id]
+	if !ok || old.Status != status {
+		return false, nil
+	}
+	a.updateResp(old, resp)
+	return true, nil
+}
+
+// updateReq replaces the stored request old with req and re-indexes its MsgID, must be called with the lock held.
+func (a *adapter) updateReq(old *t.ReqReceived, req *t.ReqReceived) {
+	if old.MsgID != req.MsgID {
+		delete(a.reqIndex, old.MsgID)
+		a.reqIndex[req.MsgID] = req.Id
+	}
+	item := *req
+	a.reqs[req.Id] = &item
+}
+
+// updateResp replaces the stored response old with resp and re-indexes its MsgID, must be called with the lock held.
+func (a *adapter) updateResp(old *t.RespReceived, resp *t.RespReceived) {
+	if old.MsgID != resp.MsgID {
+		delete(a.respIndex, old.MsgID)
+		a.respIndex[resp.MsgID] = resp.Id
+	}
+	item := *resp
+	a.resps[resp.Id] = &item
+}
+
+func (a *adapter) DeleteReq(id int64) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.open {
+		return errNotOpen
+	}
+	a.deleteReq(id)
+	return nil
+}
+
+func (a *adapter) DeleteResp(id int64) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.open {
+		return errNotOpen
+	}
+	a.deleteResp(id)
+	return nil
+}
+
+// 内存中删除无需分批，忽略policy.BatchSize
+func (a *adapter) DeleteSendedOrExpireMsg(policy t.RetentionPolicy) (int64, int64, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !a.open {
+		return 0, 0, errNotOpen
+	}
+	now := t.TimeNow()
+	var reqCount, respCount int64
+	//请求收到响应后才算完成，已送达但未响应的请求保留至过期
+	for id, v := range a.reqs {
+		if retentionPassed(policy, now, t.StatusResponded, v.Status, v.Added, v.ExpiresAt) {
+			a.deleteReq(id)
+			reqCount++
+		}
+	}
+	for id, v := range a.resps {
+		if retentionPassed(policy, now, t.StatusAcked, v.Status, v.Added, v.ExpiresAt) {
+			a.deleteResp(id)
+			respCount++
+		}
+	}
+	return reqCount, respCount, nil
+}
+
+// deleteReq removes a request and its MsgID index entry, must be called with the lock held.
+func (a *adapter) deleteReq(id int64) {
+	if v, ok := a.reqs[id]; ok {
+		delete(a.reqIndex, v.MsgID)
+		delete(a.reqs, id)
+	}
+}
+
+// deleteResp removes a response and its MsgID index entry, must be called with the lock held.
+func (a *adapter) deleteResp(id int64) {
+	if v, ok := a.resps[id]; ok {
+		delete(a.respIndex, v.MsgID)
+		delete(a.resps, id)
+	}
+}
+
+// retentionPassed checks whether a message has been kept longer than the retention of its status.
+// 'done' is the status of the messages which need nothing more.
+func retentionPassed(policy t.RetentionPolicy, now time.Time, done string, status string, added time.Time, expiresAt time.Time) bool {
+	switch status {
+	case t.StatusSucceeded, done:
+		return added.Before(now.Add(-policy.Succeeded))
+	case t.StatusTimeout:
+		return added.Before(now.Add(-policy.Failed))
+	case t.StatusDead:
+		return policy.Dead > 0 && added.Before(now.Add(-policy.Dead))
+	}
+	return expiresAt.Before(now.Add(-policy.Failed))
+}
+
+func (a *adapter) GetRetryReq() ([]t.ReqReceived, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	now := t.TimeNow()
+	items := make([]t.ReqReceived, 0)
+	for _, v := range a.reqs {
+		if (v.Status == t.StatusFailed || v.Status == t.StatusSent) && v.Retries <= configs.MaxRetryCount && !v.NextAttemptAt.After(now) && v.ExpiresAt.After(now) {
+			items = append(items, *v)
+		}
+	}
+	sortReqs(items)
+	return items, nil
+}
+
+func (a *adapter) GetRetryResp() ([]t.RespReceived, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	now := t.TimeNow()
+	items := make([]t.RespReceived, 0)
+	for _, v := range a.resps {
+		if (v.Status == t.StatusFailed || v.Status == t.StatusSent) && v.Retries <= configs.MaxRetryCount && !v.NextAttemptAt.After(now) && v.ExpiresAt.After(now) {
+			items = append(items, *v)
+		}
+	}
+	sortResps(items)
+	return items, nil
+}
+
+func (a *adapter) GetPendingReq(to string) ([]t.ReqReceived, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	now := t.TimeNow()
+	items := make([]t.ReqReceived, 0)
+	for _, v := range a.reqs {
+		if v.To == to && (v.Status == t.StatusPending || v.Status == t.StatusFailed || v.Status == t.StatusSent) && v.ExpiresAt.After(now) {
+			items = append(items, *v)
+		}
+	}
+	sortReqs(items)
+	return items, nil
+}
+
+func (a *adapter) GetPendingResp(to string) ([]t.RespReceived, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	now := t.TimeNow()
+	items := make([]t.RespReceived, 0)
+	for _, v := range a.resps {
+		if v.To == to && (v.Status == t.StatusPending || v.Status == t.StatusFailed || v.Status == t.StatusSent) && v.ExpiresAt.After(now) {
+			items = append(items, *v)
+		}
+	}
+	sortResps(items)
+	return items, nil
+}
+
+func (a *adapter) GetDeadReq(filter t.DeadLetterFilter) ([]t.ReqReceived, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	items := make([]t.ReqReceived, 0)
+	for _, v := range a.reqs {
+		if v.Status == t.StatusDead && matchDead(filter, v.MsgID, v.ReqID, v.From, v.To, v.Added) {
+			items = append(items, *v)
+		}
+	}
+	sortReqs(items)
+	if filter.Limit > 0 && len(items) > filter.Limit {
+		items = items[:filter.Limit]
+	}
+	return items, nil
+}
+
+func (a *adapter) GetDeadResp(filter t.DeadLetterFilter) ([]t.RespReceived, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	items := make([]t.RespReceived, 0)
+	for _, v := range a.resps {
+		if v.Status == t.StatusDead && matchDead(filter, v.MsgID, v.RespID, v.From, v.To, v.Added) {
+			items = append(items, *v)
+		}
+	}
+	sortResps(items)
+	if filter.Limit > 0 && len(items) > filter.Limit {
+		items = items[:filter.Limit]
+	}
+	return items, nil
+}
+
+// matchDead checks a dead letter against the conditions set in filter.
+func matchDead(filter t.DeadLetterFilter, msgID, reqID, from, to string, added time.Time) bool {
+	if filter.MsgID != "" && filter.MsgID != msgID {
+		return false
+	}
+	if filter.ClientID != "" && filter.ClientID != from && filter.ClientID != to {
+		return false
+	}
+	if filter.ReqID != "" && filter.ReqID != reqID {
+		return false
+	}
+	if !filter.Since.IsZero() && added.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && !added.Before(filter.Until) {
+		return false
+	}
+	return true
+}
+
+// 按插入顺序返回记录，与数据库按主键查询的结果保持一致
+func sortReqs(items []t.ReqReceived) {
+	sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+}
+
+func sortResps(items []t.RespReceived) {
+	sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+}
+
+func init() {
+	store.RegisterAdapter(adapterName, &adapter{})
+}
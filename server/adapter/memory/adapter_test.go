@@ -0,0 +1,134 @@
+package memory
+
+import (
+	dbadapter "github.com/dato-live/golazy/server/adapter"
+	"github.com/dato-live/golazy/server/adapter/adaptertest"
+	"github.com/dato-live/golazy/server/config"
+	t "github.com/dato-live/golazy/server/store/types"
+	"testing"
+	"time"
+)
+
+// openTestAdapter opens a new, empty adapter.
+func openTestAdapter(tb testing.TB) *adapter {
+	var conf config.Config
+	conf.MaxRetryCount = adaptertest.MaxRetryCount
+
+	a := &adapter{}
+	if err := a.Open(conf); err != nil {
+		tb.Fatal(err)
+	}
+	return a
+}
+
+// setAdded changes the time the stored message was received at.
+func setAdded(tb testing.TB, aa dbadapter.Adapter, msg interface{}, added time.Time) {
+	a := aa.(*adapter)
+	switch m := msg.(type) {
+	case *t.ReqReceived:
+		a.reqs[m.Id].Added = added
+	case *t.RespReceived:
+		a.resps[m.Id].Added = added
+	}
+}
+
+// insertReq stores a request with the given status, received at 'added'.
+func insertReq(tb testing.TB, a *adapter, req t.ReqReceived, added time.Time) *t.ReqReceived {
+	if req.ExpiresAt.IsZero() {
+		req.ExpiresAt = t.TimeNow().Add(time.Hour)
+	}
+	if err := a.InsertReq(&req); err != nil {
+		tb.Fatal(err)
+	}
+	setAdded(tb, a, &req, added)
+	return &req
+}
+
+func TestAdapter(tt *testing.T) {
+	adaptertest.Run(tt, adaptertest.Harness{
+		Open: func(tb testing.TB) (dbadapter.Adapter, func()) {
+			a := openTestAdapter(tb)
+			return a, func() { a.Close() }
+		},
+		SetAdded: setAdded,
+	})
+}
+
+func TestUpdateReqCopy(tt *testing.T) {
+	a := openTestAdapter(tt)
+	insertReq(tt, a, t.ReqReceived{MsgID: "m1", Status: t.StatusQueued}, t.TimeNow())
+
+	req, _ := a.GetReqByMsgID("m1")
+	//返回的是副本，修改后须调用UpdateReq才会保存
+	req.Status, req.Retries = t.StatusFailed, 2
+	if got, _ := a.GetReqByMsgID("m1"); got.Status != t.StatusQueued {
+		tt.Fatalf("stored request changed without UpdateReq: %+v", got)
+	}
+	if err := a.UpdateReq(&t.ReqReceived{Id: 100, MsgID: "m100"}); err == nil {
+		tt.Fatal("UpdateReq of a missing request returned no error")
+	}
+	if err := a.DeleteReq(req.Id); err != nil {
+		tt.Fatalf("DeleteReq: %v", err)
+	}
+	if len(a.reqIndex) != 0 {
+		tt.Fatalf("MsgID index not cleaned up after DeleteReq: %v", a.reqIndex)
+	}
+}
+
+func TestUpdateReqMsgID(tt *testing.T) {
+	a := openTestAdapter(tt)
+	req := insertReq(tt, a, t.ReqReceived{MsgID: "m1", Status: t.StatusQueued}, t.TimeNow())
+
+	req.MsgID = "m2"
+	if err := a.UpdateReq(req); err != nil {
+		tt.Fatalf("UpdateReq: %v", err)
+	}
+	if _, err := a.GetReqByMsgID("m1"); err == nil {
+		tt.Error("request still found by its previous MsgID")
+	}
+	if got, err := a.GetReqByMsgID("m2"); err != nil || got.Id != req.Id {
+		tt.Errorf("GetReqByMsgID(m2) returned %+v, %v", got, err)
+	}
+}
+
+func TestClosed(tt *testing.T) {
+	a := openTestAdapter(tt)
+	req := insertReq(tt, a, t.ReqReceived{MsgID: "m1", Status: t.StatusQueued}, t.TimeNow())
+	if err := a.Close(); err != nil {
+		tt.Fatalf("Close: %v", err)
+	}
+
+	//关闭后写入须返回错误而不是panic
+	if err := a.InsertReq(&t.ReqReceived{MsgID: "m2"}); err == nil {
+		tt.Error("InsertReq after Close returned no error")
+	}
+	if err := a.InsertResp(&t.RespReceived{MsgID: "m2"}); err == nil {
+		tt.Error("InsertResp after Close returned no error")
+	}
+	if err := a.UpdateReq(req); err == nil {
+		tt.Error("UpdateReq after Close returned no error")
+	}
+	if err := a.DeleteReq(req.Id); err == nil {
+		tt.Error("DeleteReq after Close returned no error")
+	}
+	if _, _, err := a.DeleteSendedOrExpireMsg(t.RetentionPolicy{}); err == nil {
+		tt.Error("DeleteSendedOrExpireMsg after Close returned no error")
+	}
+	if _, err := a.GetReqByMsgID("m1"); err == nil {
+		tt.Error("GetReqByMsgID after Close found a request")
+	}
+}
+
+func TestDeleteSendedOrExpireMsgIndex(tt *testing.T) {
+	a := openTestAdapter(tt)
+	old := t.TimeNow().Add(-3 * time.Hour)
+	insertReq(tt, a, t.ReqReceived{MsgID: "responded-old", Status: t.StatusResponded}, old)
+	insertReq(tt, a, t.ReqReceived{MsgID: "expired-old", Status: t.StatusFailed, ExpiresAt: old}, old)
+
+	if _, _, err := a.DeleteSendedOrExpireMsg(t.RetentionPolicy{Succeeded: time.Hour, Failed: time.Hour}); err != nil {
+		tt.Fatalf("DeleteSendedOrExpireMsg: %v", err)
+	}
+	if len(a.reqIndex) != 0 {
+		tt.Fatalf("MsgID index not cleaned up after DeleteSendedOrExpireMsg: %v", a.reqIndex)
+	}
+}
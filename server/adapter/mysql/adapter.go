@@ -1,5 +1,3 @@
-// +build mysql
-
 package mysql
 
 import (
@@ -25,7 +23,7 @@ type adapter struct {
 const (
 	defaultDSN      = "root@tcp(localhost)/golazy?parseTime=true&collation=utf8mb4_unicode_ci"
 	defaultDatabase = "golazy"
-	dbVersion       = "100"
+	dbVersion       = "104"
 	adapterName     = "mysql"
 )
 
@@ -129,7 +127,17 @@ func (a *adapter) CreateDb(reset bool) error {
 	if err != nil {
 		return err
 	}
-	_, err = a.db.Insert(&t.KvMeta{KeyName: "version", KeyValue: dbVersion})
+	//已初始化的数据库仅更新表结构及版本号
+	vers := &t.KvMeta{KeyName: "version", KeyValue: dbVersion}
+	has, err := a.db.Exist(&t.KvMeta{KeyName: "version"})
+	if err != nil {
+		return err
+	}
+	if has {
+		_, err = a.db.Id(vers.KeyName).Update(vers)
+	} else {
+		_, err = a.db.Insert(vers)
+	}
 	return err
 
 }
@@ -156,6 +164,18 @@ func (a *adapter) GetReqByMsgID(msgId string) (*t.ReqReceived, error) {
 	return req, nil
 }
 
+func (a *adapter) GetReqByReqID(from string, to string, reqId string) (*t.ReqReceived, error) {
+	req := &t.ReqReceived{}
+	has, err := a.db.Where("msg_from = ? AND msg_to = ? AND req_id = ?", from, to, reqId).Desc("id").Get(req)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("Record not found!")
+	}
+	return req, nil
+}
+
 func (a *adapter) GetRespByMsgID(msgId string) (*t.RespReceived, error) {
 	resp := &t.RespReceived{MsgID: msgId}
 	has, err := a.db.Get(resp)
@@ -168,18 +188,36 @@ func (a *adapter) GetRespByMsgID(msgId string) (*t.RespReceived, error) {
 	return resp, nil
 }
 
+//更新记录的全部字段，零值字段(如重置后的重传次数)同样写入
 func (a *adapter) UpdateReq(req *t.ReqReceived) error {
-	_, err := a.db.Id(req.Id).Update(req)
+	_, err := a.db.Id(req.Id).AllCols().Update(req)
 	return err
 }
 
 func (a *adapter) UpdateResp(resp *t.RespReceived) error {
-	_, err := a.db.Id(resp.Id).Update(resp)
+	_, err := a.db.Id(resp.Id).AllCols().Update(resp)
 	return err
 }
 
+func (a *adapter) UpdateReqIf(req *t.ReqReceived, status string) (bool, error) {
+	n, err := a.db.Id(req.Id).Where("status = ?", status).AllCols().Update(req)
+	if err != nil || n > 0 {
+		return n > 0, err
+	}
+	//MySQL的影响行数不计入值未改变的行，记录仍为该状态时视为已更新
+	return a.db.Where("id = ? AND status = ?", req.Id, status).Exist(&t.ReqReceived{})
+}
+
+func (a *adapter) UpdateRespIf(resp *t.RespReceived, status string) (bool, error) {
+	n, err := a.db.Id(resp.Id).Where("status = ?", status).AllCols().Update(resp)
+	if err != nil || n > 0 {
+		return n > 0, err
+	}
+	return a.db.Where("id = ? AND status = ?", resp.Id, status).Exist(&t.RespReceived{})
+}
+
 func (a *adapter) DeleteReq(id int64) error {
-	_, err := a.db.Delete(&t.RespReceived{Id: id})
+	_, err := a.db.Delete(&t.ReqReceived{Id: id})
 	return err
 }
 
@@ -188,29 +226,77 @@ func (a *adapter) DeleteResp(id int64) error {
 	return err
 }
 
-func (a *adapter) DeleteSendedOrExpireMsg() error {
-	_, err := a.db.Delete(&t.ReqReceived{Status: t.StatusSucceeded})
-	if err != nil {
-		logger.Error("DeleteSendedOrExpireMsg Delete Req failed", zap.Error(err))
+func (a *adapter) DeleteSendedOrExpireMsg(policy t.RetentionPolicy) (int64, int64, error) {
+	var reqCount, respCount int64
+	var err error
+	//请求收到响应后才算完成，已送达但未响应的请求保留至过期
+	for _, cond := range a.purgeConds(policy, t.StatusResponded) {
+		n, e := a.purge(&t.ReqReceived{}, cond, policy.BatchSize)
+		reqCount += n
+		if e != nil {
+			logger.Error("DeleteSendedOrExpireMsg Delete Req failed", zap.String("cond", cond.query), zap.Error(e))
+			err = e
+		}
 	}
-	_, err = a.db.Delete(&t.RespReceived{Status: t.StatusSucceeded})
-	if err != nil {
-		logger.Error("DeleteSendedOrExpireMsg Delete Resp failed", zap.Error(err))
+	for _, cond := range a.purgeConds(policy, t.StatusAcked) {
+		n, e := a.purge(&t.RespReceived{}, cond, policy.BatchSize)
+		respCount += n
+		if e != nil {
+			logger.Error("DeleteSendedOrExpireMsg Delete Resp failed", zap.String("cond", cond.query), zap.Error(e))
+			err = e
+		}
 	}
-	_, err = a.db.Where("expires_at>=?", t.TimeNow()).Delete(&t.ReqReceived{})
-	if err != nil {
-		logger.Error("DeleteSendedOrExpireMsg Delete Req Expire failed", zap.Error(err))
+	return reqCount, respCount, err
+}
+
+// purgeCond is a condition selecting the stored messages to delete.
+type purgeCond struct {
+	query string
+	args  []interface{}
+}
+
+// purgeConds returns the conditions selecting the messages kept longer than the retention of their status.
+// 'done' is the status of the messages which need nothing more.
+func (a *adapter) purgeConds(policy t.RetentionPolicy, done string) []purgeCond {
+	now := t.TimeNow()
+	conds := []purgeCond{
+		//已完成的消息
+		{"status IN (?, ?) AND added_time < ?", []interface{}{t.StatusSucceeded, done, now.Add(-policy.Succeeded)}},
+		//超时未响应的请求
+		{"status = ? AND added_time < ?", []interface{}{t.StatusTimeout, now.Add(-policy.Failed)}},
+		//过期仍未送达的消息
+		{"status NOT IN (?, ?, ?, ?) AND expires_at < ?", []interface{}{t.StatusSucceeded, done, t.StatusTimeout, t.StatusDead, now.Add(-policy.Failed)}},
 	}
-	_, err = a.db.Where("expires_at>=?", t.TimeNow()).Delete(&t.RespReceived{})
-	if err != nil {
-		logger.Error("DeleteSendedOrExpireMsg Delete Resp Expire failed", zap.Error(err))
+	if policy.Dead > 0 {
+		//死信
+		conds = append(conds, purgeCond{"status = ? AND added_time < ?", []interface{}{t.StatusDead, now.Add(-policy.Dead)}})
 	}
-	return err
+	return conds
+}
+
+// purge deletes the rows of the table of bean matching cond, in batches of batchSize rows so that the
+// table is not locked for long.
+func (a *adapter) purge(bean interface{}, cond purgeCond, batchSize int) (int64, error) {
+	var total int64
+	for {
+		n, err := a.purgeBatch(bean, cond, batchSize)
+		total += n
+		if err != nil || n < int64(batchSize) {
+			return total, err
+		}
+	}
+}
+
+// purgeBatch deletes at most batchSize rows of the table of bean matching cond.
+func (a *adapter) purgeBatch(bean interface{}, cond purgeCond, batchSize int) (int64, error) {
+	return a.db.Where(cond.query, cond.args...).Asc("id").Limit(batchSize).Delete(bean)
 }
 
 func (a *adapter) GetRetryReq() ([]t.ReqReceived, error) {
 	items := make([]t.ReqReceived, 0)
-	err := a.db.Where("status = ?", t.StatusFailed).And("retries <= ?", configs.MaxRetryCount).Find(&items)
+	err := a.db.In("status", t.StatusFailed, t.StatusSent).And("retries <= ?", configs.MaxRetryCount).
+		And("(next_attempt_at IS NULL OR next_attempt_at <= ?)", t.TimeNow()).
+		And("expires_at > ?", t.TimeNow()).Asc("id").Find(&items)
 	if err != nil {
 		logger.Error("GetRetryReq failed", zap.Error(err))
 		return nil, err
@@ -220,7 +306,9 @@ func (a *adapter) GetRetryReq() ([]t.ReqReceived, error) {
 
 func (a *adapter) GetRetryResp() ([]t.RespReceived, error) {
 	items := make([]t.RespReceived, 0)
-	err := a.db.Where("status = ?", t.StatusFailed).And("retries <= ?", configs.MaxRetryCount).Find(&items)
+	err := a.db.In("status", t.StatusFailed, t.StatusSent).And("retries <= ?", configs.MaxRetryCount).
+		And("(next_attempt_at IS NULL OR next_attempt_at <= ?)", t.TimeNow()).
+		And("expires_at > ?", t.TimeNow()).Asc("id").Find(&items)
 	if err != nil {
 		logger.Error("GetRetryResp failed", zap.Error(err))
 		return nil, err
@@ -228,6 +316,72 @@ func (a *adapter) GetRetryResp() ([]t.RespReceived, error) {
 	return items, err
 }
 
+func (a *adapter) GetPendingReq(to string) ([]t.ReqReceived, error) {
+	items := make([]t.ReqReceived, 0)
+	err := a.db.Where("msg_to = ?", to).In("status", t.StatusPending, t.StatusFailed, t.StatusSent).
+		And("expires_at > ?", t.TimeNow()).Asc("id").Find(&items)
+	if err != nil {
+		logger.Error("GetPendingReq failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+func (a *adapter) GetPendingResp(to string) ([]t.RespReceived, error) {
+	items := make([]t.RespReceived, 0)
+	err := a.db.Where("msg_to = ?", to).In("status", t.StatusPending, t.StatusFailed, t.StatusSent).
+		And("expires_at > ?", t.TimeNow()).Asc("id").Find(&items)
+	if err != nil {
+		logger.Error("GetPendingResp failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+func (a *adapter) GetDeadReq(filter t.DeadLetterFilter) ([]t.ReqReceived, error) {
+	items := make([]t.ReqReceived, 0)
+	err := a.deadFilter(filter, "req_id").Find(&items)
+	if err != nil {
+		logger.Error("GetDeadReq failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+func (a *adapter) GetDeadResp(filter t.DeadLetterFilter) ([]t.RespReceived, error) {
+	items := make([]t.RespReceived, 0)
+	err := a.deadFilter(filter, "resp_id").Find(&items)
+	if err != nil {
+		logger.Error("GetDeadResp failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+// deadFilter builds the query for dead letters matching filter, reqCol is the column holding the request ID.
+func (a *adapter) deadFilter(filter t.DeadLetterFilter, reqCol string) *xorm.Session {
+	sess := a.db.Where("status = ?", t.StatusDead)
+	if filter.MsgID != "" {
+		sess = sess.And("msg_id = ?", filter.MsgID)
+	}
+	if filter.ClientID != "" {
+		sess = sess.And("(msg_from = ? OR msg_to = ?)", filter.ClientID, filter.ClientID)
+	}
+	if filter.ReqID != "" {
+		sess = sess.And(reqCol+" = ?", filter.ReqID)
+	}
+	if !filter.Since.IsZero() {
+		sess = sess.And("added_time >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		sess = sess.And("added_time < ?", filter.Until)
+	}
+	if filter.Limit > 0 {
+		sess = sess.Limit(filter.Limit)
+	}
+	return sess.Asc("id")
+}
+
 // Check if MySQL error is a Error Code: 1062. Duplicate entry ... for key ...
 func isDupe(err error) bool {
 	if err == nil {
@@ -0,0 +1,64 @@
+package mysql
+
+import (
+	dbadapter "github.com/dato-live/golazy/server/adapter"
+	"github.com/dato-live/golazy/server/adapter/adaptertest"
+	"github.com/dato-live/golazy/server/config"
+	t "github.com/dato-live/golazy/server/store/types"
+	"go.uber.org/zap"
+	"os"
+	"testing"
+	"time"
+)
+
+// The tests run against the MySQL database of this DSN, which must exist and is emptied by each test.
+// They are skipped when it is not set, e.g.
+// GOLAZY_TEST_MYSQL_DSN="root@tcp(localhost)/golazy_test?parseTime=true&collation=utf8mb4_unicode_ci"
+const testDSNEnv = "GOLAZY_TEST_MYSQL_DSN"
+
+// openTestAdapter opens an adapter on the test database with empty tables, and returns it with the function closing it.
+func openTestAdapter(tb testing.TB) (dbadapter.Adapter, func()) {
+	var conf config.Config
+	conf.Store.Adapters.Mysql.DSN = os.Getenv(testDSNEnv)
+	conf.MaxRetryCount = adaptertest.MaxRetryCount
+
+	a := &adapter{}
+	if err := a.Open(conf); err != nil {
+		tb.Fatal(err)
+	}
+	//未初始化日志时使用空日志
+	logger = zap.NewNop()
+	if err := a.CreateDb(false); err != nil {
+		a.Close()
+		tb.Fatal(err)
+	}
+	for _, bean := range []interface{}{&t.ReqReceived{}, &t.RespReceived{}} {
+		if _, err := a.db.Exec("DELETE FROM " + a.db.TableName(bean)); err != nil {
+			a.Close()
+			tb.Fatal(err)
+		}
+	}
+	return a, func() { a.Close() }
+}
+
+// setAdded changes the time the stored message was received at.
+func setAdded(tb testing.TB, aa dbadapter.Adapter, msg interface{}, added time.Time) {
+	a := aa.(*adapter)
+	var id int64
+	switch m := msg.(type) {
+	case *t.ReqReceived:
+		id = m.Id
+	case *t.RespReceived:
+		id = m.Id
+	}
+	if _, err := a.db.Exec("UPDATE "+a.db.TableName(msg)+" SET added_time = ? WHERE id = ?", added, id); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestAdapter(tt *testing.T) {
+	if os.Getenv(testDSNEnv) == "" {
+		tt.Skip(testDSNEnv + " not set")
+	}
+	adaptertest.Run(tt, adaptertest.Harness{Open: openTestAdapter, SetAdded: setAdded})
+}
@@ -1,3 +0,0 @@
-// +build !mysql
-
-package mysql
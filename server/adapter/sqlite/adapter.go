@@ -1,23 +1,59 @@
-// +build sqlite
-
 package sqlite
 
+import (
+	"errors"
+	"github.com/dato-live/golazy/server/config"
+	"github.com/dato-live/golazy/server/logs"
+	"github.com/dato-live/golazy/server/store"
+	t "github.com/dato-live/golazy/server/store/types"
+	"github.com/go-xorm/xorm"
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// adapter保存SQLite连接数据
 type adapter struct {
-	db      *sqlite3.SQLiteConn
+	db      *xorm.Engine
 	dbName  string
 	version string
 }
 
 const (
 	defaultDatabase = "golazy.db"
-	dbVersion       = "100"
+	dbVersion       = "104"
 	adapterName     = "sqlite"
 )
 
+// SQLite中datetime字段以文本形式保存，查询条件需使用与xorm写入时相同的格式
+const timeFormat = "2006-01-02 15:04:05"
+
+var logger *zap.Logger
+var configs config.Config
+
 func (a *adapter) Open(conf config.Config) error {
+	configs = conf
+	logger = logs.GetLogger()
+	if a.db != nil {
+		return errors.New("sqlite adapter is already connected")
+	}
 	var err error
-	return err
+	a.dbName = conf.Store.Adapters.Sqlite.Database
+	if a.dbName == "" {
+		a.dbName = defaultDatabase
+	}
+
+	a.db, err = xorm.NewEngine("sqlite3", a.dbName)
+	if err != nil {
+		return err
+	}
+	//打印执行的SQL，仅调试模式下输出
+	a.db.ShowSQL(configs.ShowSqlToConsole)
+	//SQLite同一时间只允许一个写入者，使用单连接避免出现 database is locked 错误
+	a.db.SetMaxOpenConns(1)
 
+	return a.db.Ping()
 }
 
 // Close closes the underlying database connection
@@ -40,14 +76,17 @@ func (a *adapter) IsOpen() bool {
 // Read current database version
 func (a *adapter) getDbVersion() (string, error) {
 	var vers t.KvMeta
-	_, err := a.db.Where("key=?", "version").Get(vers)
+	has, err := a.db.Where("key_name = ?", "version").Get(&vers)
 	if err != nil {
-		if isMissingDb(err) || err == sql.ErrNoRows {
+		if isMissingDb(err) {
 			err = errors.New("Database not initialized")
 		}
 		return "", err
 	}
-	a.version = vers.Value
+	if !has {
+		return "", errors.New("Database not initialized")
+	}
+	a.version = vers.KeyValue
 
 	return a.version, nil
 }
@@ -76,9 +115,285 @@ func (a *adapter) GetName() string {
 
 // CreateDb initializes the storage.
 func (a *adapter) CreateDb(reset bool) error {
-	var err error
+	if reset {
+		err := a.db.DropTables(new(t.KvMeta), new(t.ReqReceived), new(t.RespReceived))
+		if err != nil {
+			return err
+		}
+	}
+	err := a.db.Sync2(new(t.KvMeta), new(t.ReqReceived), new(t.RespReceived))
+	if err != nil {
+		return err
+	}
+	//已初始化的数据库仅更新表结构及版本号
+	vers := &t.KvMeta{KeyName: "version", KeyValue: dbVersion}
+	has, err := a.db.Exist(&t.KvMeta{KeyName: "version"})
+	if err != nil {
+		return err
+	}
+	if has {
+		_, err = a.db.Id(vers.KeyName).Update(vers)
+	} else {
+		_, err = a.db.Insert(vers)
+	}
+	return err
+
+}
+
+func (a *adapter) InsertReq(received *t.ReqReceived) error {
+	_, err := a.db.Insert(received)
+	return err
+}
+
+func (a *adapter) InsertResp(received *t.RespReceived) error {
+	_, err := a.db.Insert(received)
 	return err
+}
+
+func (a *adapter) GetReqByMsgID(msgId string) (*t.ReqReceived, error) {
+	req := &t.ReqReceived{MsgID: msgId}
+	has, err := a.db.Get(req)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("Record not found!")
+	}
+	return req, nil
+}
+
+func (a *adapter) GetReqByReqID(from string, to string, reqId string) (*t.ReqReceived, error) {
+	req := &t.ReqReceived{}
+	has, err := a.db.Where("msg_from = ? AND msg_to = ? AND req_id = ?", from, to, reqId).Desc("id").Get(req)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("Record not found!")
+	}
+	return req, nil
+}
+
+func (a *adapter) GetRespByMsgID(msgId string) (*t.RespReceived, error) {
+	resp := &t.RespReceived{MsgID: msgId}
+	has, err := a.db.Get(resp)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, errors.New("Record not found!")
+	}
+	return resp, nil
+}
+
+//更新记录的全部字段，零值字段(如重置后的重传次数)同样写入
+func (a *adapter) UpdateReq(req *t.ReqReceived) error {
+	_, err := a.db.Id(req.Id).AllCols().Update(req)
+	return err
+}
+
+func (a *adapter) UpdateResp(resp *t.RespReceived) error {
+	_, err := a.db.Id(resp.Id).AllCols().Update(resp)
+	return err
+}
+
+func (a *adapter) UpdateReqIf(req *t.ReqReceived, status string) (bool, error) {
+	n, err := a.db.Id(req.Id).Where("status = ?", status).AllCols().Update(req)
+	return n > 0, err
+}
+
+func (a *adapter) UpdateRespIf(resp *t.RespReceived, status string) (bool, error) {
+	n, err := a.db.Id(resp.Id).Where("status = ?", status).AllCols().Update(resp)
+	return n > 0, err
+}
+
+func (a *adapter) DeleteReq(id int64) error {
+	_, err := a.db.Delete(&t.ReqReceived{Id: id})
+	return err
+}
+
+func (a *adapter) DeleteResp(id int64) error {
+	_, err := a.db.Delete(&t.RespReceived{Id: id})
+	return err
+}
+
+func (a *adapter) DeleteSendedOrExpireMsg(policy t.RetentionPolicy) (int64, int64, error) {
+	var reqCount, respCount int64
+	var err error
+	//请求收到响应后才算完成，已送达但未响应的请求保留至过期
+	for _, cond := range a.purgeConds(policy, t.StatusResponded) {
+		n, e := a.purge(&t.ReqReceived{}, cond, policy.BatchSize)
+		reqCount += n
+		if e != nil {
+			logger.Error("DeleteSendedOrExpireMsg Delete Req failed", zap.String("cond", cond.query), zap.Error(e))
+			err = e
+		}
+	}
+	for _, cond := range a.purgeConds(policy, t.StatusAcked) {
+		n, e := a.purge(&t.RespReceived{}, cond, policy.BatchSize)
+		respCount += n
+		if e != nil {
+			logger.Error("DeleteSendedOrExpireMsg Delete Resp failed", zap.String("cond", cond.query), zap.Error(e))
+			err = e
+		}
+	}
+	return reqCount, respCount, err
+}
+
+// purgeCond is a condition selecting the stored messages to delete.
+type purgeCond struct {
+	query string
+	args  []interface{}
+}
+
+// purgeConds returns the conditions selecting the messages kept longer than the retention of their status.
+// 'done' is the status of the messages which need nothing more.
+func (a *adapter) purgeConds(policy t.RetentionPolicy, done string) []purgeCond {
+	now := t.TimeNow()
+	conds := []purgeCond{
+		//已完成的消息
+		{"status IN (?, ?) AND added_time < ?", []interface{}{t.StatusSucceeded, done, a.formatTime(now.Add(-policy.Succeeded))}},
+		//超时未响应的请求
+		{"status = ? AND added_time < ?", []interface{}{t.StatusTimeout, a.formatTime(now.Add(-policy.Failed))}},
+		//过期仍未送达的消息
+		{"status NOT IN (?, ?, ?, ?) AND expires_at < ?", []interface{}{t.StatusSucceeded, done, t.StatusTimeout, t.StatusDead, a.formatTime(now.Add(-policy.Failed))}},
+	}
+	if policy.Dead > 0 {
+		//死信
+		conds = append(conds, purgeCond{"status = ? AND added_time < ?", []interface{}{t.StatusDead, a.formatTime(now.Add(-policy.Dead))}})
+	}
+	return conds
+}
+
+// purge deletes the rows of the table of bean matching cond, in batches of batchSize rows so that the
+// table is not locked for long.
+func (a *adapter) purge(bean interface{}, cond purgeCond, batchSize int) (int64, error) {
+	var total int64
+	for {
+		n, err := a.purgeBatch(bean, cond, batchSize)
+		total += n
+		if err != nil || n < int64(batchSize) {
+			return total, err
+		}
+	}
+}
+
+// purgeBatch deletes at most batchSize rows of the table of bean matching cond. xorm limits deletes
+// on SQLite with a rowid subquery which ignores cond, so the subquery is written here.
+func (a *adapter) purgeBatch(bean interface{}, cond purgeCond, batchSize int) (int64, error) {
+	table := a.db.Quote(a.db.TableName(bean))
+	query := "DELETE FROM " + table + " WHERE id IN (SELECT id FROM " + table + " WHERE " + cond.query + " ORDER BY id LIMIT ?)"
+	res, err := a.db.Exec(append([]interface{}{query}, append(cond.args, batchSize)...)...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (a *adapter) GetRetryReq() ([]t.ReqReceived, error) {
+	items := make([]t.ReqReceived, 0)
+	err := a.db.In("status", t.StatusFailed, t.StatusSent).And("retries <= ?", configs.MaxRetryCount).
+		And("(next_attempt_at IS NULL OR next_attempt_at <= ?)", a.formatTime(t.TimeNow())).
+		And("expires_at > ?", a.formatTime(t.TimeNow())).Asc("id").Find(&items)
+	if err != nil {
+		logger.Error("GetRetryReq failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+func (a *adapter) GetRetryResp() ([]t.RespReceived, error) {
+	items := make([]t.RespReceived, 0)
+	err := a.db.In("status", t.StatusFailed, t.StatusSent).And("retries <= ?", configs.MaxRetryCount).
+		And("(next_attempt_at IS NULL OR next_attempt_at <= ?)", a.formatTime(t.TimeNow())).
+		And("expires_at > ?", a.formatTime(t.TimeNow())).Asc("id").Find(&items)
+	if err != nil {
+		logger.Error("GetRetryResp failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+func (a *adapter) GetPendingReq(to string) ([]t.ReqReceived, error) {
+	items := make([]t.ReqReceived, 0)
+	err := a.db.Where("msg_to = ?", to).In("status", t.StatusPending, t.StatusFailed, t.StatusSent).
+		And("expires_at > ?", a.formatTime(t.TimeNow())).Asc("id").Find(&items)
+	if err != nil {
+		logger.Error("GetPendingReq failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+func (a *adapter) GetPendingResp(to string) ([]t.RespReceived, error) {
+	items := make([]t.RespReceived, 0)
+	err := a.db.Where("msg_to = ?", to).In("status", t.StatusPending, t.StatusFailed, t.StatusSent).
+		And("expires_at > ?", a.formatTime(t.TimeNow())).Asc("id").Find(&items)
+	if err != nil {
+		logger.Error("GetPendingResp failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+func (a *adapter) GetDeadReq(filter t.DeadLetterFilter) ([]t.ReqReceived, error) {
+	items := make([]t.ReqReceived, 0)
+	err := a.deadFilter(filter, "req_id").Find(&items)
+	if err != nil {
+		logger.Error("GetDeadReq failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+func (a *adapter) GetDeadResp(filter t.DeadLetterFilter) ([]t.RespReceived, error) {
+	items := make([]t.RespReceived, 0)
+	err := a.deadFilter(filter, "resp_id").Find(&items)
+	if err != nil {
+		logger.Error("GetDeadResp failed", zap.Error(err))
+		return nil, err
+	}
+	return items, err
+}
+
+// deadFilter builds the query for dead letters matching filter, reqCol is the column holding the request ID.
+func (a *adapter) deadFilter(filter t.DeadLetterFilter, reqCol string) *xorm.Session {
+	sess := a.db.Where("status = ?", t.StatusDead)
+	if filter.MsgID != "" {
+		sess = sess.And("msg_id = ?", filter.MsgID)
+	}
+	if filter.ClientID != "" {
+		sess = sess.And("(msg_from = ? OR msg_to = ?)", filter.ClientID, filter.ClientID)
+	}
+	if filter.ReqID != "" {
+		sess = sess.And(reqCol+" = ?", filter.ReqID)
+	}
+	if !filter.Since.IsZero() {
+		sess = sess.And("added_time >= ?", a.formatTime(filter.Since))
+	}
+	if !filter.Until.IsZero() {
+		sess = sess.And("added_time < ?", a.formatTime(filter.Until))
+	}
+	if filter.Limit > 0 {
+		sess = sess.Limit(filter.Limit)
+	}
+	return sess.Asc("id")
+}
+
+// formatTime converts tm to the textual form xorm uses when writing datetime columns,
+// so that it can be compared with stored values.
+func (a *adapter) formatTime(tm time.Time) string {
+	return tm.In(a.db.DatabaseTZ).Format(timeFormat)
+}
+
+// Check if SQLite error is a "no such table" error, which means the database has not been created yet.
+func isMissingDb(err error) bool {
+	if err == nil {
+		return false
+	}
 
+	sqerr, ok := err.(sqlite3.Error)
+	return ok && sqerr.Code == sqlite3.ErrError && strings.HasPrefix(sqerr.Error(), "no such table")
 }
 
 func init() {
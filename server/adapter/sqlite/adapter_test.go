@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	dbadapter "github.com/dato-live/golazy/server/adapter"
+	"github.com/dato-live/golazy/server/adapter/adaptertest"
+	"github.com/dato-live/golazy/server/config"
+	t "github.com/dato-live/golazy/server/store/types"
+	"go.uber.org/zap"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestAdapter opens an adapter on a new database in a temporary directory, and returns it with
+// the function closing it and removing the database.
+func openTestAdapter(tb testing.TB) (dbadapter.Adapter, func()) {
+	dir, err := ioutil.TempDir("", "golazy-sqlite")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var conf config.Config
+	conf.Store.Adapters.Sqlite.Database = filepath.Join(dir, "golazy.db")
+	conf.MaxRetryCount = adaptertest.MaxRetryCount
+
+	a := &adapter{}
+	if err := a.Open(conf); err != nil {
+		tb.Fatal(err)
+	}
+	//未初始化日志时使用空日志
+	logger = zap.NewNop()
+	if err := a.CreateDb(false); err != nil {
+		tb.Fatal(err)
+	}
+	return a, func() {
+		a.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// setAdded changes the time the stored message was received at.
+func setAdded(tb testing.TB, aa dbadapter.Adapter, msg interface{}, added time.Time) {
+	a := aa.(*adapter)
+	var id int64
+	switch m := msg.(type) {
+	case *t.ReqReceived:
+		id = m.Id
+	case *t.RespReceived:
+		id = m.Id
+	}
+	if _, err := a.db.Exec("UPDATE "+a.db.TableName(msg)+" SET added_time = ? WHERE id = ?", a.formatTime(added), id); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestAdapter(tt *testing.T) {
+	adaptertest.Run(tt, adaptertest.Harness{Open: openTestAdapter, SetAdded: setAdded})
+}
@@ -1,3 +0,0 @@
-// +build !sqlite
-
-package sqlite
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+var ErrAdminDisabled = errors.New("admin RPCs are disabled, no auth.admin_tokens configured")
+
+// VerifyAdmin checks the token presented to an admin RPC against the configured admin tokens.
+func VerifyAdmin(token string) error {
+	if len(adminTokens) == 0 {
+		return ErrAdminDisabled
+	}
+	if token == "" {
+		return ErrMissingCredentials
+	}
+	for _, t := range adminTokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return errors.New("invalid admin token")
+}
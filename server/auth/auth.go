@@ -0,0 +1,111 @@
+//客户端认证包，客户端发送Hi时校验其提供的凭证
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dato-live/golazy/server/config"
+	"sort"
+	"time"
+)
+
+// Credentials 客户端在Hi消息中提供的凭证，Method为空时使用唯一启用的认证方式
+type Credentials struct {
+	Method    string
+	Token     string
+	Nonce     string
+	Timestamp *time.Time
+	Signature string
+}
+
+// Verifier 客户端凭证校验器接口
+type Verifier interface {
+	//使用配置初始化校验器
+	Open(conf config.AuthConfig) error
+	//获取校验器名称，即凭证中的Method
+	GetName() string
+	//校验客户端clientID提供的凭证，校验失败时返回原因
+	Verify(clientID string, cred *Credentials) error
+}
+
+var ErrMissingCredentials = errors.New("missing credentials")
+
+// 所有已注册的校验器，按名称索引
+var verifiers map[string]Verifier
+
+// 配置文件 auth.methods 启用的校验器
+var enabled map[string]Verifier
+
+// 配置文件 auth.admin_tokens 中的管理员令牌
+var adminTokens []string
+
+func Register(name string, v Verifier) {
+	if v == nil {
+		panic("auth: Register verifier is nil")
+	}
+
+	if verifiers == nil {
+		verifiers = make(map[string]Verifier)
+	}
+
+	if _, dup := verifiers[name]; dup {
+		panic("auth: verifier '" + name + "' is already registered")
+	}
+
+	verifiers[name] = v
+}
+
+// VerifierNames returns the names of the registered verifiers, sorted.
+func VerifierNames() []string {
+	names := make([]string, 0, len(verifiers))
+	for name := range verifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open initializes the verifiers enabled by conf.Methods. Authentication is disabled if none is enabled.
+func Open(conf config.AuthConfig) error {
+	adminTokens = conf.AdminTokens
+	enabled = make(map[string]Verifier)
+	for _, name := range conf.Methods {
+		v, ok := verifiers[name]
+		if !ok {
+			return fmt.Errorf("auth: unknown method '%s', registered methods: %v", name, VerifierNames())
+		}
+		if err := v.Open(conf); err != nil {
+			return err
+		}
+		enabled[name] = v
+	}
+	return nil
+}
+
+// Enabled checks whether clients must present credentials.
+func Enabled() bool {
+	return len(enabled) > 0
+}
+
+// Verify checks the credentials presented by the client clientID with the verifier of their method.
+func Verify(clientID string, cred *Credentials) error {
+	if cred == nil {
+		return ErrMissingCredentials
+	}
+	method := cred.Method
+	if method == "" && len(enabled) == 1 {
+		for name := range enabled {
+			method = name
+		}
+	}
+	v, ok := enabled[method]
+	if !ok {
+		return fmt.Errorf("unsupported method '%s'", cred.Method)
+	}
+	return v.Verify(clientID, cred)
+}
+
+// withinSkew checks whether tm is within the allowed clock skew of now.
+func withinSkew(tm time.Time, now time.Time, skew time.Duration) bool {
+	return !tm.Before(now.Add(-skew)) && !tm.After(now.Add(skew))
+}
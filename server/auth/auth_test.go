@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/dato-live/golazy/server/config"
+	"github.com/dato-live/golazy/server/store/types"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testSkew = 30 * time.Second
+
+// openTest enables the given methods with credentials for the client "c1".
+func openTest(t *testing.T, methods ...string) {
+	conf := config.AuthConfig{
+		Methods:            methods,
+		Tokens:             map[string]string{"c1": "token-c1"},
+		HmacSecrets:        map[string]string{"c1": "hmac-c1"},
+		JwtSecret:          "jwt-secret",
+		JwtIssuer:          "golazy-test",
+		MaxClockSkewSecond: int(testSkew / time.Second),
+	}
+	if err := Open(conf); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+}
+
+// signJwt returns a JWT with the given header and claims, signed with HMAC-SHA256 and secret.
+func signJwt(t *testing.T, header map[string]interface{}, claims map[string]interface{}, secret string) string {
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(header) + "." + encode(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signHmac returns the hmac credentials of clientID for nonce at tm, signed with secret.
+func signHmac(clientID string, nonce string, tm time.Time, secret string) *Credentials {
+	ts := tm.UnixNano() / int64(time.Millisecond)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(clientID + "\n" + nonce + "\n" + strconv.FormatInt(ts, 10)))
+	return &Credentials{Method: hmacMethod, Nonce: nonce, Timestamp: &tm, Signature: hex.EncodeToString(mac.Sum(nil))}
+}
+
+func TestOpenUnknownMethod(t *testing.T) {
+	if err := Open(config.AuthConfig{Methods: []string{"kerberos"}}); err == nil {
+		t.Fatal("Open accepted an unknown method")
+	}
+}
+
+func TestJwt(t *testing.T) {
+	openTest(t, jwtMethod)
+	now := types.TimeNow().Unix()
+	hs256 := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"sub": "c1", "iss": "golazy-test", "exp": now + 60}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+	skew := int64(testSkew / time.Second)
+
+	cases := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"valid", signJwt(t, hs256, claims(nil), "jwt-secret"), true},
+		{"alg none", signJwt(t, map[string]interface{}{"alg": "none"}, claims(nil), "jwt-secret"), false},
+		{"alg HS512", signJwt(t, map[string]interface{}{"alg": "HS512"}, claims(nil), "jwt-secret"), false},
+		{"bad signature", signJwt(t, hs256, claims(nil), "other-secret"), false},
+		{"malformed", "not-a-jwt", false},
+		{"sub mismatch", signJwt(t, hs256, claims(map[string]interface{}{"sub": "c2"}), "jwt-secret"), false},
+		{"issuer mismatch", signJwt(t, hs256, claims(map[string]interface{}{"iss": "other"}), "jwt-secret"), false},
+		{"no exp", signJwt(t, hs256, claims(map[string]interface{}{"exp": nil}), "jwt-secret"), false},
+		{"expired", signJwt(t, hs256, claims(map[string]interface{}{"exp": now - skew - 5}), "jwt-secret"), false},
+		{"expired within skew", signJwt(t, hs256, claims(map[string]interface{}{"exp": now - skew + 5}), "jwt-secret"), true},
+		{"not valid yet", signJwt(t, hs256, claims(map[string]interface{}{"nbf": now + skew + 5}), "jwt-secret"), false},
+		{"not valid yet within skew", signJwt(t, hs256, claims(map[string]interface{}{"nbf": now + skew - 5}), "jwt-secret"), true},
+	}
+	for _, c := range cases {
+		err := Verify("c1", &Credentials{Method: jwtMethod, Token: c.token})
+		if c.ok && err != nil {
+			t.Errorf("%s: rejected: %v", c.name, err)
+		} else if !c.ok && err == nil {
+			t.Errorf("%s: accepted", c.name)
+		}
+	}
+
+	//令牌的sub须与Hi中的ClientID一致
+	if err := Verify("c2", &Credentials{Method: jwtMethod, Token: signJwt(t, hs256, claims(nil), "jwt-secret")}); err == nil {
+		t.Error("token of c1 accepted for c2")
+	}
+}
+
+func TestHmac(t *testing.T) {
+	openTest(t, hmacMethod)
+	now := types.TimeNow()
+
+	if err := Verify("c1", signHmac("c1", "n1", now, "hmac-c1")); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	//同一Nonce不能重复使用
+	if err := Verify("c1", signHmac("c1", "n1", now, "hmac-c1")); err == nil {
+		t.Error("replayed nonce accepted")
+	}
+	if err := Verify("c1", signHmac("c1", "n2", now, "hmac-c1")); err != nil {
+		t.Errorf("new nonce rejected: %v", err)
+	}
+
+	cases := []struct {
+		name string
+		cred *Credentials
+	}{
+		{"bad signature", signHmac("c1", "n3", now, "other-secret")},
+		{"signed for another client", signHmac("c2", "n4", now, "hmac-c1")},
+		{"timestamp too old", signHmac("c1", "n5", now.Add(-testSkew-time.Second), "hmac-c1")},
+		{"timestamp in the future", signHmac("c1", "n6", now.Add(testSkew+time.Second), "hmac-c1")},
+		{"no nonce", signHmac("c1", "", now, "hmac-c1")},
+	}
+	for _, c := range cases {
+		if err := Verify("c1", c.cred); err == nil {
+			t.Errorf("%s: accepted", c.name)
+		}
+	}
+	//未配置密钥的客户端
+	if err := Verify("c2", signHmac("c2", "n7", now, "hmac-c1")); err == nil {
+		t.Error("client without secret accepted")
+	}
+}
+
+func TestToken(t *testing.T) {
+	openTest(t, tokenMethod)
+	if err := Verify("c1", &Credentials{Method: tokenMethod, Token: "token-c1"}); err != nil {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	for _, token := range []string{"", "token-c2"} {
+		if err := Verify("c1", &Credentials{Method: tokenMethod, Token: token}); err == nil {
+			t.Errorf("token %q accepted", token)
+		}
+	}
+}
+
+func TestVerifyMethod(t *testing.T) {
+	//仅启用一种认证方式时，Method为空使用该方式
+	openTest(t, tokenMethod)
+	if err := Verify("c1", &Credentials{Token: "token-c1"}); err != nil {
+		t.Errorf("empty method with one enabled method rejected: %v", err)
+	}
+	if err := Verify("c1", &Credentials{Token: "wrong"}); err == nil {
+		t.Error("empty method resolved to the enabled method accepted a wrong token")
+	}
+	if err := Verify("c1", &Credentials{Method: hmacMethod, Token: "token-c1"}); err == nil {
+		t.Error("method not enabled accepted")
+	}
+	if err := Verify("c1", nil); err != ErrMissingCredentials {
+		t.Errorf("no credentials returned %v", err)
+	}
+
+	//启用多种认证方式时须指定Method
+	openTest(t, tokenMethod, jwtMethod)
+	if err := Verify("c1", &Credentials{Token: "token-c1"}); err == nil {
+		t.Error("empty method with several enabled methods accepted")
+	}
+	if err := Verify("c1", &Credentials{Method: tokenMethod, Token: "token-c1"}); err != nil {
+		t.Errorf("explicit method rejected: %v", err)
+	}
+
+	openTest(t)
+	if Enabled() {
+		t.Error("authentication enabled without methods")
+	}
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/dato-live/golazy/server/config"
+	"github.com/dato-live/golazy/server/store/types"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// hmacVerifier HMAC签名认证，客户端使用为其ClientID配置的密钥对ClientID、随机数Nonce及时间戳签名:
+// Signature = hex(HMAC-SHA256(secret, ClientID + "\n" + Nonce + "\n" + Timestamp))，Timestamp为毫秒时间戳。
+// 时间戳须在允许的时钟偏差内，且同一Nonce在此期间不能重复使用
+type hmacVerifier struct {
+	secrets map[string]string
+	skew    time.Duration
+
+	lock sync.Mutex
+	// Nonces already used, with the time after which they can be forgotten
+	nonces map[string]time.Time
+}
+
+const hmacMethod = "hmac"
+
+func (v *hmacVerifier) Open(conf config.AuthConfig) error {
+	if len(conf.HmacSecrets) == 0 {
+		return errors.New("auth: method 'hmac' requires auth.hmac_secrets")
+	}
+	v.secrets = conf.HmacSecrets
+	v.skew = time.Duration(conf.MaxClockSkewSecond) * time.Second
+	v.nonces = make(map[string]time.Time)
+	return nil
+}
+
+func (v *hmacVerifier) GetName() string {
+	return hmacMethod
+}
+
+func (v *hmacVerifier) Verify(clientID string, cred *Credentials) error {
+	secret, ok := v.secrets[clientID]
+	if !ok || cred.Nonce == "" || cred.Timestamp == nil {
+		return errors.New("invalid signature")
+	}
+	now := types.TimeNow()
+	if !withinSkew(*cred.Timestamp, now, v.skew) {
+		return errors.New("signature timestamp out of range")
+	}
+
+	ts := cred.Timestamp.UnixNano() / int64(time.Millisecond)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(clientID + "\n" + cred.Nonce + "\n" + strconv.FormatInt(ts, 10)))
+	signature, err := hex.DecodeString(cred.Signature)
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return errors.New("invalid signature")
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for nonce, expires := range v.nonces {
+		if expires.Before(now) {
+			delete(v.nonces, nonce)
+		}
+	}
+	key := clientID + "\n" + cred.Nonce
+	if _, used := v.nonces[key]; used {
+		return errors.New("nonce already used")
+	}
+	v.nonces[key] = cred.Timestamp.Add(v.skew)
+	return nil
+}
+
+func init() {
+	Register(hmacMethod, &hmacVerifier{})
+}
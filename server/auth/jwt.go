@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/dato-live/golazy/server/config"
+	"github.com/dato-live/golazy/server/store/types"
+	"strings"
+	"time"
+)
+
+// jwtVerifier JWT认证，客户端在Token中提供以HS256签名的JWT，其sub须为ClientID，且须设置过期时间exp
+type jwtVerifier struct {
+	secret []byte
+	issuer string
+	skew   time.Duration
+}
+
+const jwtMethod = "jwt"
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss"`
+	ExpiresAt int64  `json:"exp"`
+	NotBefore int64  `json:"nbf"`
+}
+
+func (v *jwtVerifier) Open(conf config.AuthConfig) error {
+	if conf.JwtSecret == "" {
+		return errors.New("auth: method 'jwt' requires auth.jwt_secret")
+	}
+	v.secret = []byte(conf.JwtSecret)
+	v.issuer = conf.JwtIssuer
+	v.skew = time.Duration(conf.MaxClockSkewSecond) * time.Second
+	return nil
+}
+
+func (v *jwtVerifier) GetName() string {
+	return jwtMethod
+}
+
+func (v *jwtVerifier) Verify(clientID string, cred *Credentials) error {
+	parts := strings.Split(cred.Token, ".")
+	if len(parts) != 3 {
+		return errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeJwtPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return errors.New("unsupported token algorithm")
+	}
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return errors.New("invalid token signature")
+	}
+
+	var claims jwtClaims
+	if err := decodeJwtPart(parts[1], &claims); err != nil {
+		return errors.New("malformed token")
+	}
+	now := types.TimeNow()
+	switch {
+	case claims.Subject != clientID:
+		return errors.New("token subject does not match ClientID")
+	case v.issuer != "" && claims.Issuer != v.issuer:
+		return errors.New("token issuer not accepted")
+	case claims.ExpiresAt == 0 || now.Add(-v.skew).After(time.Unix(claims.ExpiresAt, 0)):
+		return errors.New("token expired")
+	case claims.NotBefore != 0 && now.Add(v.skew).Before(time.Unix(claims.NotBefore, 0)):
+		return errors.New("token not valid yet")
+	}
+	return nil
+}
+
+// decodeJwtPart decodes a base64url encoded JSON part of a JWT into out.
+func decodeJwtPart(part string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func init() {
+	Register(jwtMethod, &jwtVerifier{})
+}
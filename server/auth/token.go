@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/dato-live/golazy/server/config"
+)
+
+// tokenVerifier 静态令牌认证，客户端提供为其ClientID配置的令牌
+type tokenVerifier struct {
+	tokens map[string]string
+}
+
+const tokenMethod = "token"
+
+func (v *tokenVerifier) Open(conf config.AuthConfig) error {
+	if len(conf.Tokens) == 0 {
+		return errors.New("auth: method 'token' requires auth.tokens")
+	}
+	v.tokens = conf.Tokens
+	return nil
+}
+
+func (v *tokenVerifier) GetName() string {
+	return tokenMethod
+}
+
+func (v *tokenVerifier) Verify(clientID string, cred *Credentials) error {
+	token, ok := v.tokens[clientID]
+	if !ok || cred.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(cred.Token)) != 1 {
+		return errors.New("invalid token")
+	}
+	return nil
+}
+
+func init() {
+	Register(tokenMethod, &tokenVerifier{})
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dato-live/golazy/server/store/types"
+	"sync"
+	"time"
+)
+
+// 请求的To为该值时发送给所有在线客户端
+const BroadcastAll = "*"
+
+// pendingBroadcast is a broadcast request collecting the responses of its targets.
+type pendingBroadcast struct {
+	reqID string
+	// Sender of the request, receives the aggregated response
+	from string
+	// Target of the request as addressed by the sender: a service, a client or BroadcastAll
+	to string
+
+	// ClientIDs of the targets, in the order they are reported
+	targets []string
+	// MsgIDs of the requests delivered to the targets, indexed by ClientID
+	msgIDs map[string]string
+	// Responses received so far, indexed by ClientID
+	results map[string]*DMClientResp
+	// Number of responses to wait for
+	quorum int
+
+	// Closed once the quorum is reached
+	done chan struct{}
+}
+
+// BroadcastStore holds broadcast requests waiting for the responses of their targets, indexed by sender and ReqID.
+type BroadcastStore struct {
+	lock sync.Mutex
+
+	broadcasts map[reqKey]*pendingBroadcast
+}
+
+// NewBroadcastStore initializes a broadcast store.
+func NewBroadcastStore() *BroadcastStore {
+	return &BroadcastStore{
+		broadcasts: make(map[reqKey]*pendingBroadcast),
+	}
+}
+
+// isBroadcast checks whether the request is sent to every matching client rather than to one.
+func isBroadcast(req *DMClientReq) bool {
+	return req.Broadcast || req.To == BroadcastAll
+}
+
+// broadcastTargets returns the sessions a broadcast request is delivered to: every client for BroadcastAll,
+// otherwise the client with ClientID req.To or the members of the service req.To. The sender and the
+// clients not accepting the command are left out.
+func broadcastTargets(req *DMClientReq) []*Session {
+	var candidates []*Session
+	if req.To == BroadcastAll {
+		candidates = globals.sessionStore.GetClients()
+	} else if sess := globals.sessionStore.GetByClientID(req.To); sess != nil {
+		candidates = []*Session{sess}
+	} else {
+		candidates = globals.sessionStore.ServiceMembers(req.To)
+	}
+
+	targets := make([]*Session, 0, len(candidates))
+	for _, sess := range candidates {
+		//不发送给发送方自身、未声明该命令及访问控制拒绝的客户端
+		if sess.clientInfo.ClientID != req.From && sess.isCommandAllowed(req.CommandID) && checkACL(req, sess.clientInfo.ClientID, "broadcast") {
+			targets = append(targets, sess)
+		}
+	}
+	return targets
+}
+
+// broadcastTimeout returns how long to wait for the responses of the targets: the timeout of the
+// request if it has one, otherwise the configured broadcast timeout.
+func broadcastTimeout(req *DMClientReq) time.Duration {
+	if req.TimeoutMs > 0 {
+		return time.Duration(req.TimeoutMs) * time.Millisecond
+	}
+	return time.Duration(globals.configs.BroadcastTimeoutSecond) * time.Second
+}
+
+// Start registers the broadcast request and delivers it to the targets. Returns nil if a broadcast
+// of the same sender with the same ReqID is already waiting for responses.
+func (bs *BroadcastStore) Start(req *DMClientReq, targets []*Session) *pendingBroadcast {
+	b := &pendingBroadcast{
+		reqID:   req.ReqID,
+		from:    req.From,
+		to:      req.To,
+		msgIDs:  make(map[string]string),
+		results: make(map[string]*DMClientResp),
+		quorum:  int(req.Quorum),
+		done:    make(chan struct{}),
+	}
+	if b.quorum <= 0 || b.quorum > len(targets) {
+		b.quorum = len(targets)
+	}
+	reqMsgs := make([]*DMClientMsg, len(targets))
+	for i, sess := range targets {
+		reqMsgs[i] = newRouteReqMsg(req)
+		b.targets = append(b.targets, sess.clientInfo.ClientID)
+		b.msgIDs[sess.clientInfo.ClientID] = reqMsgs[i].MsgID
+	}
+
+	key := reqKey{from: req.From, reqID: req.ReqID}
+	bs.lock.Lock()
+	if _, ok := bs.broadcasts[key]; ok {
+		bs.lock.Unlock()
+		return nil
+	}
+	bs.broadcasts[key] = b
+	bs.lock.Unlock()
+
+	for i, sess := range targets {
+		saveReq(reqMsgs[i], sess.clientInfo.ClientID, types.StatusQueued)
+		sess.deliver(reqMsgs[i])
+	}
+	return b
+}
+
+// Deliver records the response of one of the targets of a broadcast, which must be sent to the sender of
+// the broadcast, and returns the MsgID of the request delivered to that target, empty for a duplicated
+// response. Returns false if no broadcast is waiting for this response, then it should be routed as usual.
+func (bs *BroadcastStore) Deliver(resp *DMClientResp) (string, bool) {
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+
+	//ReqID仅对同一发送方唯一，须同时按响应的接收方(即广播的发送方)查找
+	b, ok := bs.broadcasts[reqKey{from: resp.To, reqID: resp.RespID}]
+	if !ok {
+		return "", false
+	}
+	isTarget := false
+	for _, clientID := range b.targets {
+		if clientID == resp.From {
+			isTarget = true
+			break
+		}
+	}
+	if !isTarget {
+		return "", false
+	}
+	if _, answered := b.results[resp.From]; answered {
+		//重复的响应，忽略
+		return "", true
+	}
+	b.results[resp.From] = resp
+	if len(b.results) == b.quorum {
+		close(b.done)
+	}
+	return b.msgIDs[resp.From], true
+}
+
+// Finish stops waiting for the responses of the broadcast and returns the aggregated response,
+// with one result for each target, in the order of the targets. The requests of the targets which did
+// not respond are expired: they are not sent again, no longer count as outstanding, and their late responses are rejected.
+func (bs *BroadcastStore) Finish(b *pendingBroadcast) *DMClientResp {
+	resp, missing := bs.finish(b)
+	for _, clientID := range missing {
+		expireReq(b.msgIDs[clientID])
+	}
+	return resp
+}
+
+// finish removes the broadcast and aggregates its responses, returns the targets which did not respond.
+func (bs *BroadcastStore) finish(b *pendingBroadcast) (*DMClientResp, []string) {
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+
+	delete(bs.broadcasts, reqKey{from: b.from, reqID: b.reqID})
+
+	now := types.TimeNow()
+	resp := &DMClientResp{
+		RespID:    b.reqID,
+		From:      b.to,
+		To:        b.from,
+		Timestamp: &now,
+	}
+	var missing []string
+	for _, clientID := range b.targets {
+		result := &DMTargetResp{ClientID: clientID}
+		if r, ok := b.results[clientID]; ok {
+			result.Content, result.ErrCode, result.ErrMsg = r.Content, r.ErrCode, r.ErrMsg
+		} else {
+			result.ErrCode, result.ErrMsg = RespErrTimeout, "Target did not respond in time"
+			missing = append(missing, clientID)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	if len(b.results) < b.quorum {
+		resp.ErrCode = RespErrQuorumNotReached
+		resp.ErrMsg = fmt.Sprintf("Received %d of %d required responses", len(b.results), b.quorum)
+	}
+	return resp, missing
+}
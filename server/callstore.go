@@ -0,0 +1,69 @@
+package main
+
+import (
+	"sync"
+)
+
+// pendingCall is a unary Call waiting for the response of its target.
+type pendingCall struct {
+	// ClientID of the target, only responses sent by it are accepted
+	to string
+
+	// Buffered, receives exactly one response
+	resp chan *DMClientResp
+}
+
+// CallStore holds unary Call requests waiting for a response, indexed by caller and ReqID.
+type CallStore struct {
+	lock sync.Mutex
+
+	calls map[reqKey]*pendingCall
+}
+
+// NewCallStore initializes a call store.
+func NewCallStore() *CallStore {
+	return &CallStore{
+		calls: make(map[reqKey]*pendingCall),
+	}
+}
+
+// Add registers a call of the client 'from' waiting for the response to reqID from the client 'to'.
+// Returns false if a call of the same client with the same reqID is already waiting.
+func (cs *CallStore) Add(from string, reqID string, to string) (<-chan *DMClientResp, bool) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	key := reqKey{from: from, reqID: reqID}
+	if _, ok := cs.calls[key]; ok {
+		return nil, false
+	}
+	call := &pendingCall{to: to, resp: make(chan *DMClientResp, 1)}
+	cs.calls[key] = call
+	return call.resp, true
+}
+
+// Remove stops waiting for the response to reqID of the client 'from'.
+func (cs *CallStore) Remove(from string, reqID string) {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	delete(cs.calls, reqKey{from: from, reqID: reqID})
+}
+
+// Deliver hands the response over to the call waiting for it. The response must be sent by the target
+// of the call to its caller. Returns false if no call is waiting for this response, then it should be
+// routed as usual.
+func (cs *CallStore) Deliver(resp *DMClientResp) bool {
+	cs.lock.Lock()
+	defer cs.lock.Unlock()
+
+	//ReqID仅对同一调用方唯一，须同时按响应的接收方(即调用方)查找
+	key := reqKey{from: resp.To, reqID: resp.RespID}
+	call, ok := cs.calls[key]
+	if !ok || call.to != resp.From {
+		return false
+	}
+	delete(cs.calls, key)
+	call.resp <- resp
+	return true
+}
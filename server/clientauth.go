@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/dato-live/golazy/server/auth"
+	"github.com/dato-live/golazy/server/protos"
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"strconv"
+)
+
+// gRPC metadata中管理员令牌的键
+const adminTokenKey = "x-golazy-admin-token"
+
+// gRPC metadata中一元调用(Call, ListClients)的客户端ID及凭证的键，凭证字段与Hi消息的Credentials一致，
+// 时间戳为毫秒级Unix时间
+const (
+	clientIDKey      = "x-golazy-client-id"
+	authMethodKey    = "x-golazy-auth-method"
+	authTokenKey     = "x-golazy-auth-token"
+	authNonceKey     = "x-golazy-auth-nonce"
+	authTimestampKey = "x-golazy-auth-timestamp"
+	authSignatureKey = "x-golazy-auth-signature"
+)
+
+// authenticate verifies the credentials presented in the Hi message, if client authentication is enabled.
+func (sess *Session) authenticate(hi *DMClientHi) error {
+	if !auth.Enabled() {
+		return nil
+	}
+	if hi.Credentials == nil {
+		return auth.ErrMissingCredentials
+	}
+	return auth.Verify(hi.ClientID, &auth.Credentials{
+		Method:    hi.Credentials.Method,
+		Token:     hi.Credentials.Token,
+		Nonce:     hi.Credentials.Nonce,
+		Timestamp: hi.Credentials.Timestamp,
+		Signature: hi.Credentials.Signature,
+	})
+}
+
+// authenticateCall verifies the identity of the caller of a unary RPC with the same checks as Hi: the
+// ClientID sent in the gRPC metadata must match the client certificate if tls.bind_client_id is set,
+// and its credentials must pass the enabled verifiers. Returns the verified ClientID, which is the
+// unverified ClientID of the metadata (possibly empty) if neither check is enabled.
+func authenticateCall(ctx context.Context) (string, error) {
+	clientID := metadataValue(ctx, clientIDKey)
+	if globals.configs.TLS.BindClientID {
+		var names []string
+		if p, ok := peer.FromContext(ctx); ok {
+			names = certNames(p)
+		}
+		//未设置客户端ID时使用证书的CN
+		if clientID == "" && len(names) > 0 {
+			clientID = names[0]
+		}
+		if !nameMatches(names, clientID) {
+			logger.Warn(fmt.Sprintf("[Identity Mismatch] ClientID '%s' does not match client certificate %v", clientID, names), zap.String("remote", peerAddr(ctx)))
+			return "", status.Errorf(codes.Unauthenticated, "Unauthenticated, ClientID [%s] does not match the client certificate", clientID)
+		}
+	}
+	if !auth.Enabled() {
+		return clientID, nil
+	}
+
+	cred := &auth.Credentials{
+		Method:    metadataValue(ctx, authMethodKey),
+		Token:     metadataValue(ctx, authTokenKey),
+		Nonce:     metadataValue(ctx, authNonceKey),
+		Signature: metadataValue(ctx, authSignatureKey),
+	}
+	if ts := metadataValue(ctx, authTimestampKey); ts != "" {
+		ms, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return "", status.Errorf(codes.Unauthenticated, "Unauthenticated, invalid timestamp '%s'", ts)
+		}
+		cred.Timestamp = int64ToTime(ms)
+	}
+	err := auth.ErrMissingCredentials
+	if clientID != "" && (cred.Token != "" || cred.Signature != "") {
+		err = auth.Verify(clientID, cred)
+	}
+	if err != nil {
+		logger.Warn(fmt.Sprintf("[Unauthorized] Client '%s' failed authentication: %v", clientID, err), zap.String("remote", peerAddr(ctx)))
+		return "", status.Errorf(codes.Unauthenticated, "Unauthenticated, %v", err)
+	}
+	return clientID, nil
+}
+
+// metadataValue returns the first value of the gRPC metadata key sent with the call, empty if not sent.
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerAddr returns the remote address of the caller, empty if unknown.
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// peerHost returns the remote host of the caller, without the port, empty if unknown.
+func peerHost(ctx context.Context) string {
+	addr := peerAddr(ctx)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// checkAdmin verifies the admin token sent with a call to an admin RPC.
+func checkAdmin(ctx context.Context) error {
+	err := auth.VerifyAdmin(metadataValue(ctx, adminTokenKey))
+	if err == nil {
+		return nil
+	}
+	logger.Warn(fmt.Sprintf("[Admin Denied] %v", err), zap.String("remote", peerAddr(ctx)))
+	if err == auth.ErrAdminDisabled {
+		return status.Errorf(codes.PermissionDenied, "Permission denied, %v", err)
+	}
+	return status.Errorf(codes.Unauthenticated, "Unauthenticated, %v", err)
+}
+
+// inLogString returns the text of a received message for logging, without the credentials of Hi.
+func inLogString(in *golazy.ClientMsg) string {
+	hi := in.GetHi()
+	if hi == nil || hi.Credentials == nil {
+		return in.String()
+	}
+	hi = proto.Clone(hi).(*golazy.ClientHi)
+	hi.Credentials = &golazy.Credentials{Method: hi.Credentials.Method}
+	return (&golazy.ClientMsg{MsgID: in.MsgID, Message: &golazy.ClientMsg_Hi{Hi: hi}}).String()
+}
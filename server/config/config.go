@@ -5,6 +5,7 @@ import (
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"log"
+	"math"
 	"path/filepath"
 )
 
@@ -23,6 +24,68 @@ type AdapterConfig struct {
 	Sqlite      SqliteConfig `yaml:"sqlite"`
 }
 
+// TLSConfig gRPC监听端口的TLS配置，未设置cert_file时不启用TLS
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	//设置后启用双向TLS(mTLS)，客户端须提供由该CA签发的证书
+	ClientCAFile string `yaml:"client_ca_file"`
+	//最低TLS版本：1.0，1.1，1.2，1.3
+	MinVersion string `yaml:"min_version"`
+	//mTLS时要求客户端Hi消息中的ClientID与证书的CN或DNS名称之一一致
+	BindClientID bool `yaml:"bind_client_id"`
+}
+
+// AuthConfig 客户端认证配置，methods为空时不校验客户端凭证
+type AuthConfig struct {
+	//启用的认证方式：token，hmac，jwt
+	Methods []string `yaml:"methods"`
+	//token: ClientID对应的静态令牌
+	Tokens map[string]string `yaml:"tokens"`
+	//hmac: ClientID对应的签名密钥
+	HmacSecrets map[string]string `yaml:"hmac_secrets"`
+	//jwt: HS256签名密钥，及要求的签发者(为空时不校验)
+	JwtSecret string `yaml:"jwt_secret"`
+	JwtIssuer string `yaml:"jwt_issuer"`
+	//hmac签名时间戳及jwt有效期允许的时钟偏差，单位秒
+	MaxClockSkewSecond int `yaml:"max_clock_skew_second"`
+	//死信管理接口的管理员令牌，为空时禁用死信管理接口
+	AdminTokens []string `yaml:"admin_tokens"`
+}
+
+// ACLRule 路由访问控制规则：ClientID匹配From的客户端可向匹配To的目标发送Commands中的命令，
+// From/To中可使用'*'匹配任意字符，Commands为空表示允许所有命令
+type ACLRule struct {
+	From     string  `yaml:"from"`
+	To       string  `yaml:"to"`
+	Commands []int64 `yaml:"commands"`
+}
+
+// ACLConfig 路由访问控制配置
+type ACLConfig struct {
+	//请求未匹配任何规则(From及To)时的处理方式：allow，deny
+	Default string    `yaml:"default"`
+	Rules   []ACLRule `yaml:"rules"`
+}
+
+// RateLimit 令牌桶限流参数：每秒允许的消息数及突发消息数，rate为0表示不限制
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// RateLimitConfig 客户端发送请求(Req)及响应(Resp)的速率限制
+type RateLimitConfig struct {
+	//每个会话(连接)
+	Session RateLimit `yaml:"session"`
+	//每个ClientID，重新连接后仍然有效
+	Client RateLimit `yaml:"client"`
+	//所有客户端合计
+	Global RateLimit `yaml:"global"`
+	//按ClientID覆盖session及client限制
+	Overrides map[string]RateLimit `yaml:"overrides"`
+}
+
 type StoreConfig struct {
 	Adapters AdapterConfig `yaml:"adapters"`
 }
@@ -35,6 +98,12 @@ type Config struct {
 	GrpcListen       string `yaml:"grpc_listen"`
 	MaxMessageSize   int64  `yaml:"max_message_size"`
 
+	TLS  TLSConfig  `yaml:"tls"`
+	Auth AuthConfig `yaml:"auth"`
+	ACL  ACLConfig  `yaml:"acl"`
+
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+
 	Store StoreConfig `yaml:"store"`
 
 	IdleSessionTimeoutSecond    int `yaml:"idle_session_timeout_second"`
@@ -42,6 +111,26 @@ type Config struct {
 	RetrySecondInterval         int `yaml:"retry_second_interval"`
 	CleanDbMinuteInterval       int `yaml:"clean_db_minute_interval"`
 	MessageExpireMinuteInterval int `yaml:"message_expire_minute_interval"`
+	CallTimeoutSecond           int `yaml:"call_timeout_second"`
+	ShutdownTimeoutSecond       int `yaml:"shutdown_timeout_second"`
+	AckTimeoutSecond            int `yaml:"ack_timeout_second"`
+	BroadcastTimeoutSecond      int `yaml:"broadcast_timeout_second"`
+
+	//客户端Hi消息中未声明AllowedCommandIDs时是否接受所有命令，默认不接受任何命令
+	AllowUndeclaredCommands bool `yaml:"allow_undeclared_commands"`
+
+	LoadBalance string `yaml:"load_balance"`
+	//服务名对应允许加入的ClientID，可使用'*'匹配任意字符
+	ServiceMembers map[string][]string `yaml:"service_members"`
+
+	RetryBackoffBaseSecond int     `yaml:"retry_backoff_base_second"`
+	RetryBackoffMaxSecond  int     `yaml:"retry_backoff_max_second"`
+	RetryBackoffMultiplier float64 `yaml:"retry_backoff_multiplier"`
+
+	RetentionSucceededMinute int `yaml:"retention_succeeded_minute"`
+	RetentionFailedMinute    int `yaml:"retention_failed_minute"`
+	RetentionDeadMinute      int `yaml:"retention_dead_minute"`
+	CleanDbBatchSize         int `yaml:"clean_db_batch_size"`
 }
 
 func LoadConfig(configPath string) Config {
@@ -57,7 +146,7 @@ func LoadConfig(configPath string) Config {
 	}
 	err = yaml.Unmarshal(yamlFile, &config)
 	if err != nil {
-		log.Fatal("Parse config file [%s] error:%v\n", fullPath, err)
+		log.Fatalf("Parse config file [%s] error:%v\n", fullPath, err)
 	}
 	return config
 }
@@ -75,16 +164,127 @@ func (c *Config) CheckConfig() {
 		c.CleanDbMinuteInterval = types.DefaultCleanDbMinuteInterval
 	}
 
+	if c.RetentionSucceededMinute <= 0 {
+		c.RetentionSucceededMinute = types.DefaultRetentionSucceededMinute
+	}
+
+	if c.RetentionFailedMinute < 0 {
+		c.RetentionFailedMinute = 0
+	}
+
+	if c.RetentionDeadMinute < 0 {
+		c.RetentionDeadMinute = 0
+	}
+
+	if c.CleanDbBatchSize <= 0 {
+		c.CleanDbBatchSize = types.DefaultCleanDbBatchSize
+	}
+
 	if c.RetrySecondInterval <= 0 {
 		c.RetrySecondInterval = types.DefaultRetrySecondInterval
 	}
 
+	if c.RetryBackoffBaseSecond <= 0 {
+		c.RetryBackoffBaseSecond = types.DefaultRetryBackoffBaseSecond
+	}
+
+	if c.RetryBackoffMaxSecond < c.RetryBackoffBaseSecond {
+		c.RetryBackoffMaxSecond = types.DefaultRetryBackoffMaxSecond
+		if c.RetryBackoffMaxSecond < c.RetryBackoffBaseSecond {
+			c.RetryBackoffMaxSecond = c.RetryBackoffBaseSecond
+		}
+	}
+
+	if c.RetryBackoffMultiplier < 1 {
+		c.RetryBackoffMultiplier = types.DefaultRetryBackoffMultiplier
+	}
+
 	if c.IdleSessionTimeoutSecond <= 30 {
 		c.IdleSessionTimeoutSecond = types.DefaultIdleSessionTimeoutSecond
 	}
 
+	if c.Store.Adapters.AdapterType == "" {
+		c.Store.Adapters.AdapterType = types.DefaultAdapterType
+	}
+
 	if c.MessageExpireMinuteInterval <= 0 {
 		c.MessageExpireMinuteInterval = types.DefaultMessageExpireMinuteInterval
 	}
 
+	if c.CallTimeoutSecond <= 0 {
+		c.CallTimeoutSecond = types.DefaultCallTimeoutSecond
+	}
+
+	if c.ShutdownTimeoutSecond <= 0 {
+		c.ShutdownTimeoutSecond = types.DefaultShutdownTimeoutSecond
+	}
+
+	if c.BroadcastTimeoutSecond <= 0 {
+		c.BroadcastTimeoutSecond = types.DefaultBroadcastTimeoutSecond
+	}
+
+	if c.AckTimeoutSecond <= 0 {
+		c.AckTimeoutSecond = types.DefaultAckTimeoutSecond
+	}
+
+	if c.TLS.CertFile != "" || c.TLS.KeyFile != "" {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			log.Fatalf("Both tls cert_file and key_file must be set\n")
+		}
+		if c.TLS.MinVersion == "" {
+			c.TLS.MinVersion = types.DefaultTLSMinVersion
+		}
+	}
+	if c.TLS.ClientCAFile != "" && c.TLS.CertFile == "" {
+		log.Fatalf("tls client_ca_file requires cert_file and key_file\n")
+	}
+	if c.TLS.BindClientID && c.TLS.ClientCAFile == "" {
+		log.Fatalf("tls bind_client_id requires client_ca_file\n")
+	}
+
+	if c.Auth.MaxClockSkewSecond <= 0 {
+		c.Auth.MaxClockSkewSecond = types.DefaultMaxClockSkewSecond
+	}
+
+	switch c.ACL.Default {
+	case types.ACLAllow, types.ACLDeny:
+	case "":
+		c.ACL.Default = types.DefaultACLDefault
+	default:
+		log.Fatalf("Unknown acl default [%s], expected %s or %s\n", c.ACL.Default, types.ACLAllow, types.ACLDeny)
+	}
+	for i, rule := range c.ACL.Rules {
+		if rule.From == "" || rule.To == "" {
+			log.Fatalf("acl rule #%d: both from and to must be set\n", i+1)
+		}
+	}
+
+	c.RateLimit.Session.check()
+	c.RateLimit.Client.check()
+	c.RateLimit.Global.check()
+	for clientID, limit := range c.RateLimit.Overrides {
+		limit.check()
+		c.RateLimit.Overrides[clientID] = limit
+	}
+
+	switch c.LoadBalance {
+	case types.LoadBalanceRoundRobin, types.LoadBalanceLeastOutstanding, types.LoadBalanceConsistentHash:
+	case "":
+		c.LoadBalance = types.DefaultLoadBalance
+	default:
+		log.Printf("Unknown load_balance [%s], using %s\n", c.LoadBalance, types.DefaultLoadBalance)
+		c.LoadBalance = types.DefaultLoadBalance
+	}
+
+}
+
+// check disables the limit if its rate is not positive, and defaults the burst to one second of messages.
+func (l *RateLimit) check() {
+	if l.Rate <= 0 {
+		l.Rate, l.Burst = 0, 0
+		return
+	}
+	if l.Burst <= 0 {
+		l.Burst = int(math.Ceil(l.Rate))
+	}
 }
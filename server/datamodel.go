@@ -7,8 +7,19 @@ type DMClientHi struct {
 	ClientName        string           `json:"clientname"`
 	ClientVersion     string           `json:"clientversion"`
 	ClientDescription string           `json:"clientdescription"`
-	AllowedCommandIDs map[int64]string `json:"allowedcommandids"`
+	AllowedCommandIDs map[int64]string `json:"allowedcommandids"` //为空时不接受任何命令，除非配置了allow_undeclared_commands
 	Timestamp         *time.Time       `json:"timestamp"`
+	SubscribePresence bool             `json:"subscribepresence"`
+	ServiceName       string           `json:"servicename"`
+	Credentials       *DMCredentials   `json:"-"`
+}
+
+type DMCredentials struct {
+	Method    string
+	Token     string
+	Nonce     string
+	Timestamp *time.Time
+	Signature string
 }
 
 type DMClientLeave struct {
@@ -23,6 +34,11 @@ type DMClientReq struct {
 	CommandID int64      `json:"commandid"`
 	Content   string     `json:"content"`
 	Timestamp *time.Time `json:"timestamp"`
+	RouteKey  string     `json:"routekey"`
+	Broadcast bool       `json:"broadcast"`
+	Quorum    int32      `json:"quorum"`
+	//等待响应的超时时间(毫秒)，0表示不限制
+	TimeoutMs int64 `json:"timeoutms"`
 }
 
 type DMClientResp struct {
@@ -33,20 +49,106 @@ type DMClientResp struct {
 	ErrCode   int32      `json:"errcode"`
 	ErrMsg    string     `json:"errmsg"`
 	Timestamp *time.Time `json:"timestamp"`
+	//广播请求汇总的各目标响应
+	Results []*DMTargetResp `json:"results,omitempty"`
+}
+
+type DMTargetResp struct {
+	ClientID string `json:"clientid"`
+	Content  string `json:"content"`
+	ErrCode  int32  `json:"errcode"`
+	ErrMsg   string `json:"errmsg"`
 }
 
+// 服务器生成的ClientResp错误码
+const (
+	RespErrNone             = 0
+	RespErrTimeout          = 2001 // 目标未在规定时间内响应
+	RespErrQuorumNotReached = 2002 // 广播请求未收到足够数量的响应
+)
+
 type DMAckMsg struct {
-	MsgID     string     `json:"msgid"`
-	IsOk      bool       `json:"isok"`
-	Msg       string     `json:"msg"`
+	MsgID        string     `json:"msgid"`
+	IsOk         bool       `json:"isok"`
+	Msg          string     `json:"msg"`
+	Timestamp    *time.Time `json:"timestamp"`
+	ErrCode      int32      `json:"errcode"`
+	RetryAfterMs int64      `json:"retryafterms"`
+}
+
+// AckMsg错误码，用于区分处理结果
+const (
+	AckErrNone               = 0
+	AckQueued                = 1 // IsOk为true，目标离线，消息已存储，待目标上线后发送
+	AckErrDuplicatedClient   = 1001
+	AckErrTargetNotFound     = 1002
+	AckErrCommandNotAllowed  = 1003
+	AckErrInvalidTopic       = 1004
+	AckErrDuplicatedRequest  = 1005
+	AckErrOrphanResponse     = 1006 // 响应未对应任何等待响应的请求
+	AckErrResponderMismatch  = 1007 // 响应方不是请求的目标
+	AckErrDuplicatedResponse = 1008 // 请求已响应或已超时
+	AckErrIdentityMismatch   = 1009 // ClientID与客户端证书不一致
+	AckErrUnauthorized       = 1010 // 客户端凭证校验失败
+	AckErrAccessDenied       = 1011 // 路由访问控制规则不允许该请求
+	AckErrProtocol           = 1012 // 协议错误：未完成Hi即发送消息，或From与会话的ClientID不一致
+	AckErrRateLimited        = 1013 // 超过发送速率限制，消息未处理，RetryAfterMs后可重新发送
+	AckErrServiceDenied      = 1014 // 客户端不允许加入Hi消息中的服务，或ClientID与服务同名
+)
+
+type DMClientPresence struct {
+	ClientID      string     `json:"clientid"`
+	ClientName    string     `json:"clientname"`
+	ClientVersion string     `json:"clientversion"`
+	Event         string     `json:"event"`
+	Reason        string     `json:"reason"`
+	Timestamp     *time.Time `json:"timestamp"`
+}
+
+// 上下线通知事件类型
+const (
+	PresenceJoin   = "join"
+	PresenceLeave  = "leave"
+	PresenceReject = "reject"
+)
+
+type DMPing struct {
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+type DMPong struct {
+	PingTimestamp *time.Time `json:"pingtimestamp"`
+	Timestamp     *time.Time `json:"timestamp"`
+}
+
+type DMSubscribe struct {
+	Topic string `json:"topic"`
+}
+
+type DMUnsubscribe struct {
+	Topic string `json:"topic"`
+}
+
+type DMPublish struct {
+	Topic     string     `json:"topic"`
+	From      string     `json:"from"`
+	Content   string     `json:"content"`
 	Timestamp *time.Time `json:"timestamp"`
 }
 
 type DMClientMsg struct {
-	Hi    *DMClientHi    `json:"hi,omitempty"`
-	Leave *DMClientLeave `json:"leave,omitempty"`
-	Req   *DMClientReq   `json:"req,omitempty"`
-	Resp  *DMClientResp  `json:"resp,omitempty"`
-	Ack   *DMAckMsg      `json:"ack,omitempty"`
-	MsgID string         `json:"msgid"`
+	Hi       *DMClientHi       `json:"hi,omitempty"`
+	Leave    *DMClientLeave    `json:"leave,omitempty"`
+	Req      *DMClientReq      `json:"req,omitempty"`
+	Resp     *DMClientResp     `json:"resp,omitempty"`
+	Ack      *DMAckMsg         `json:"ack,omitempty"`
+	Presence *DMClientPresence `json:"presence,omitempty"`
+	Ping     *DMPing           `json:"ping,omitempty"`
+	Pong     *DMPong           `json:"pong,omitempty"`
+
+	Subscribe   *DMSubscribe   `json:"subscribe,omitempty"`
+	Unsubscribe *DMUnsubscribe `json:"unsubscribe,omitempty"`
+	Publish     *DMPublish     `json:"publish,omitempty"`
+
+	MsgID string `json:"msgid"`
 }
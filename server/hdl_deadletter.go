@@ -0,0 +1,212 @@
+/******************************************************************************
+ *
+ *  Description :
+ *
+ *    Admin handlers of dead letters: messages which could not be delivered
+ *    after MaxRetryCount retries.
+ *
+ *****************************************************************************/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/dato-live/golazy/server/protos"
+	"github.com/dato-live/golazy/server/store"
+	"github.com/dato-live/golazy/server/store/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+	"time"
+)
+
+// ListDeadLetters returns the dead letters matching the filter, in the order they were received.
+// The content of the messages is not included, use GetDeadLetter to inspect a message.
+func (*grpcNodeServer) ListDeadLetters(ctx context.Context, req *golazy.DeadLetterFilter) (*golazy.DeadLetterList, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	filter := newDeadLetterFilter(req)
+	reqItems, respItems, err := getDeadLetters(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &golazy.DeadLetterList{}
+	for i := range reqItems {
+		resp.DeadLetters = append(resp.DeadLetters, reqDeadLetter(&reqItems[i], false))
+	}
+	for i := range respItems {
+		resp.DeadLetters = append(resp.DeadLetters, respDeadLetter(&respItems[i], false))
+	}
+	sort.Slice(resp.DeadLetters, func(i, j int) bool {
+		return types.ParseMsgUid(resp.DeadLetters[i].MsgID) < types.ParseMsgUid(resp.DeadLetters[j].MsgID)
+	})
+	if filter.Limit > 0 && len(resp.DeadLetters) > filter.Limit {
+		resp.DeadLetters = resp.DeadLetters[:filter.Limit]
+	}
+	return resp, nil
+}
+
+// GetDeadLetter returns a dead letter with its content.
+func (*grpcNodeServer) GetDeadLetter(ctx context.Context, req *golazy.GetDeadLetterReq) (*golazy.DeadLetter, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.GetMsgID() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "MsgID is required")
+	}
+	reqItems, respItems, err := getDeadLetters(types.DeadLetterFilter{MsgID: req.GetMsgID()})
+	if err != nil {
+		return nil, err
+	}
+	if len(reqItems) > 0 {
+		return reqDeadLetter(&reqItems[0], true), nil
+	}
+	if len(respItems) > 0 {
+		return respDeadLetter(&respItems[0], true), nil
+	}
+	return nil, status.Errorf(codes.NotFound, "Dead letter [%s] not found", req.GetMsgID())
+}
+
+// RequeueDeadLetters gives the dead letters matching the filter a new round of retries: they are
+// sent again by the retry loop if the target is online, or when it says Hi otherwise.
+func (*grpcNodeServer) RequeueDeadLetters(ctx context.Context, req *golazy.DeadLetterFilter) (*golazy.DeadLetterResult, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkDeadLetterCriteria(req); err != nil {
+		return nil, err
+	}
+	reqItems, respItems, err := getDeadLetters(newDeadLetterFilter(req))
+	if err != nil {
+		return nil, err
+	}
+
+	now := types.TimeNow()
+	expiresAt := types.GetExpiresTime(globals.configs.MessageExpireMinuteInterval)
+	var count int64
+	for i := range reqItems {
+		item := &reqItems[i]
+		item.Status, item.Retries, item.NextAttemptAt, item.ExpiresAt = types.StatusFailed, 0, now, expiresAt
+		//仅重新投递仍为死信的消息
+		ok, err := store.MsgObj.UpdateReqIf(item, types.StatusDead)
+		if err != nil {
+			return &golazy.DeadLetterResult{Count: count}, status.Errorf(codes.Internal, "Requeue [%s] failed: %v", item.MsgID, err)
+		}
+		if ok {
+			count++
+		}
+	}
+	for i := range respItems {
+		item := &respItems[i]
+		item.Status, item.Retries, item.NextAttemptAt, item.ExpiresAt = types.StatusFailed, 0, now, expiresAt
+		ok, err := store.MsgObj.UpdateRespIf(item, types.StatusDead)
+		if err != nil {
+			return &golazy.DeadLetterResult{Count: count}, status.Errorf(codes.Internal, "Requeue [%s] failed: %v", item.MsgID, err)
+		}
+		if ok {
+			count++
+		}
+	}
+	logger.Info(fmt.Sprintf("[Dead Letter] Requeued %d messages", count))
+	return &golazy.DeadLetterResult{Count: count}, nil
+}
+
+// PurgeDeadLetters deletes the dead letters matching the filter.
+func (*grpcNodeServer) PurgeDeadLetters(ctx context.Context, req *golazy.DeadLetterFilter) (*golazy.DeadLetterResult, error) {
+	if err := checkAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := checkDeadLetterCriteria(req); err != nil {
+		return nil, err
+	}
+	reqItems, respItems, err := getDeadLetters(newDeadLetterFilter(req))
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	for _, item := range reqItems {
+		if err := store.MsgObj.DeleteReq(item.Id); err != nil {
+			return &golazy.DeadLetterResult{Count: count}, status.Errorf(codes.Internal, "Purge [%s] failed: %v", item.MsgID, err)
+		}
+		count++
+	}
+	for _, item := range respItems {
+		if err := store.MsgObj.DeleteResp(item.Id); err != nil {
+			return &golazy.DeadLetterResult{Count: count}, status.Errorf(codes.Internal, "Purge [%s] failed: %v", item.MsgID, err)
+		}
+		count++
+	}
+	logger.Info(fmt.Sprintf("[Dead Letter] Purged %d messages", count))
+	return &golazy.DeadLetterResult{Count: count}, nil
+}
+
+// checkDeadLetterCriteria rejects a filter which would match every dead letter unless All is set,
+// so that a request with the conditions left out by mistake does not requeue or purge them all.
+func checkDeadLetterCriteria(req *golazy.DeadLetterFilter) error {
+	if req.GetMsgID() == "" && req.GetClientID() == "" && req.GetReqID() == "" && req.GetSince() == 0 && req.GetUntil() == 0 && !req.GetAll() {
+		return status.Errorf(codes.InvalidArgument, "At least one condition is required, set All to match every dead letter")
+	}
+	return nil
+}
+
+func newDeadLetterFilter(req *golazy.DeadLetterFilter) types.DeadLetterFilter {
+	filter := types.DeadLetterFilter{
+		MsgID:    req.GetMsgID(),
+		ClientID: req.GetClientID(),
+		ReqID:    req.GetReqID(),
+		Limit:    int(req.GetLimit()),
+	}
+	if since := int64ToTime(req.GetSince()); since != nil {
+		filter.Since = *since
+	}
+	if until := int64ToTime(req.GetUntil()); until != nil {
+		filter.Until = *until
+	}
+	return filter
+}
+
+func getDeadLetters(filter types.DeadLetterFilter) ([]types.ReqReceived, []types.RespReceived, error) {
+	reqItems, err := store.MsgObj.GetDeadReq(filter)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "Get dead letters failed: %v", err)
+	}
+	respItems, err := store.MsgObj.GetDeadResp(filter)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "Get dead letters failed: %v", err)
+	}
+	return reqItems, respItems, nil
+}
+
+func reqDeadLetter(item *types.ReqReceived, withContent bool) *golazy.DeadLetter {
+	dl := newDeadLetter(item.MsgID, "req", item.ReqID, item.From, item.To, item.Retries, item.LastError, item.Added, item.ExpiresAt)
+	if withContent {
+		dl.Content = item.Content
+	}
+	return dl
+}
+
+func respDeadLetter(item *types.RespReceived, withContent bool) *golazy.DeadLetter {
+	dl := newDeadLetter(item.MsgID, "resp", item.RespID, item.From, item.To, item.Retries, item.LastError, item.Added, item.ExpiresAt)
+	if withContent {
+		dl.Content = item.Content
+	}
+	return dl
+}
+
+func newDeadLetter(msgID, msgType, reqID, from, to string, retries int, lastError string, added, expiresAt time.Time) *golazy.DeadLetter {
+	return &golazy.DeadLetter{
+		MsgID:     msgID,
+		MsgType:   msgType,
+		ReqID:     reqID,
+		From:      from,
+		To:        to,
+		Retries:   int32(retries),
+		LastError: lastError,
+		Added:     timeToInt64(&added),
+		ExpiresAt: timeToInt64(&expiresAt),
+	}
+}
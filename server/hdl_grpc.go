@@ -10,32 +10,52 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/dato-live/golazy/server/protos"
 	"github.com/dato-live/golazy/server/store"
 	"github.com/dato-live/golazy/server/store/types"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
 	"io"
 	"net"
+	"strings"
 	"time"
 )
 
 type grpcNodeServer struct {
 }
 
-func (sess *Session) closeGrpc() {
+// How many times to retry saving the status of a message which is changed concurrently
+const maxStatusUpdateAttempts = 3
+
+func (sess *Session) closeGrpc(reason string) {
+	sess.lock.Lock()
 	if sess.proto == GRPC {
-		sess.lock.Lock()
 		sess.grpcNode = nil
-		sess.lock.Unlock()
 	}
+	closed := sess.closed
+	sess.closed = true
+	sess.lock.Unlock()
 
-	//连接断开，保存未发送的数据，同时删除当前会话Session
-	//TODO:持久化存储信息队列
+	//会话已被关闭过，避免重复删除及重复发送下线通知
+	if closed {
+		return
+	}
+	if sess.done != nil {
+		close(sess.done)
+	}
+
+	//连接断开，删除当前会话Session
 	globals.sessionStore.Delete(sess)
-	logger.Warn(fmt.Sprintf("[Session Removed] Seesion: '%s' (ClientID=%s) has been removed, because grpc has closed !!!", sess.sid, sess.clientInfo.ClientID))
+	logger.Warn(fmt.Sprintf("[Session Removed] Seesion: '%s' (ClientID=%s) has been removed, because grpc has closed (%s) !!!", sess.sid, sess.clientInfo.ClientID, reason))
 
+	if sess.hasClientInfo() {
+		globals.sessionStore.BroadcastPresence(sess, &sess.clientInfo, PresenceLeave, reason)
+	}
 }
 
 //
@@ -44,12 +64,32 @@ func (sess *Session) closeGrpc() {
 func (*grpcNodeServer) MessageLoop(stream golazy.Node_MessageLoopServer) error {
 	sess, _ := globals.sessionStore.NewSession(stream, "")
 
+	closeReason := CloseReasonLeave
 	defer func() {
-		sess.closeGrpc()
+		sess.closeGrpc(closeReason)
 	}()
 
+	globals.sessionStore.writers.Add(1)
 	go sess.writeGrpcLoop()
 
+	recvErr := make(chan error, 1)
+	go func() {
+		recvErr <- sess.readGrpcLoop(stream)
+	}()
+
+	//会话被服务器关闭时(如空闲超时)直接返回，结束当前GRPC流，阻塞中的Recv随之返回
+	select {
+	case err := <-recvErr:
+		if err != nil {
+			closeReason = CloseReasonStreamError
+		}
+		return err
+	case <-sess.done:
+		return nil
+	}
+}
+
+func (sess *Session) readGrpcLoop(stream golazy.Node_MessageLoopServer) error {
 	for {
 		in, err := stream.Recv()
 		if err == io.EOF {
@@ -60,7 +100,8 @@ func (*grpcNodeServer) MessageLoop(stream golazy.Node_MessageLoopServer) error {
 			logger.Warn("grpc: recv", zap.String("session", sess.sid), zap.Error(err))
 			return err
 		}
-		logger.Debug(fmt.Sprintf("grpc in"), zap.String("in", in.String()), zap.String("session", sess.sid))
+		logger.Debug(fmt.Sprintf("grpc in"), zap.String("in", inLogString(in)), zap.String("session", sess.sid))
+		globals.sessionStore.Touch(sess)
 		sess.dispatchMsg(PbDeserialize(in))
 
 		sess.lock.Lock()
@@ -76,175 +117,589 @@ func (*grpcNodeServer) MessageLoop(stream golazy.Node_MessageLoopServer) error {
 
 func (sess *Session) dispatchMsg(msg *DMClientMsg) {
 
+	if errMsg := sess.checkProtocol(msg); errMsg != "" {
+		logger.Warn(fmt.Sprintf("[Protocol Error] %s", errMsg), zap.String("MsgID", msg.MsgID), zap.String("session", sess.sid))
+		sess.queueAck(msg.MsgID, false, AckErrProtocol, fmt.Sprintf("Protocol error, %s", errMsg))
+		return
+	}
+
+	//限流，超过限制的消息不存储也不转发
+	if msg.Req != nil || msg.Resp != nil {
+		if retryAfter, scope := globals.rateLimiter.Allow(sess, sess.clientInfo.ClientID); retryAfter > 0 {
+			logger.Debug(fmt.Sprintf("[Rate Limited] Client [%s] exceeded the %s rate limit", sess.clientInfo.ClientID, scope), zap.String("MsgID", msg.MsgID), zap.String("session", sess.sid))
+			sess.queueRetryAck(msg.MsgID, AckErrRateLimited, fmt.Sprintf("Rate limited, %s rate limit exceeded, retry after %s", scope, retryAfter), retryAfter)
+			return
+		}
+	}
+
 	switch {
 	case msg.Hi != nil:
 		now := types.TimeNow()
+		//校验客户端凭证，通过后才注册会话
+		if err := sess.authenticate(msg.Hi); err != nil {
+			logger.Warn(fmt.Sprintf("[Unauthorized] Client '%s' failed authentication: %v, this connection will be dropped!", msg.Hi.ClientID, err), zap.String("session", sess.sid))
+			sess.queueAck(msg.MsgID, false, AckErrUnauthorized, fmt.Sprintf("Unauthorized, %v", err))
+			time.Sleep(2 * time.Second)
+			sess.closeGrpc(CloseReasonAuth)
+			return
+		}
+		//证书身份须先于重复连接检查，避免未持有证书的连接借重复ClientID广播拒绝事件
+		if globals.configs.TLS.BindClientID && !sess.certMatches(msg.Hi.ClientID) {
+			logger.Warn(fmt.Sprintf("[Identity Mismatch] ClientID '%s' does not match client certificate %v, this connection will be dropped!", msg.Hi.ClientID, sess.certNames), zap.String("session", sess.sid))
+			sess.queueAck(msg.MsgID, false, AckErrIdentityMismatch, fmt.Sprintf("Identity mismatch, ClientID [%s] does not match the client certificate", msg.Hi.ClientID))
+			time.Sleep(2 * time.Second)
+			sess.closeGrpc(CloseReasonIdentity)
+			return
+		}
 		existClient := globals.sessionStore.GetByClientID(msg.Hi.ClientID)
 		if existClient != nil {
 			logger.Warn(fmt.Sprintf("[Duplicated Client] Client: '%s' already connected, this connection will be dropped!", msg.Hi.ClientID), zap.String("ClientID", msg.Hi.ClientID))
-			sess.queueOut(&DMClientMsg{Ack: &DMAckMsg{MsgID: msg.MsgID, IsOk: false, Msg: fmt.Sprintf("Duplicated client, Client [%s] already connected", msg.Hi.ClientID), Timestamp: &now}})
+			sess.queueOut(&DMClientMsg{Ack: &DMAckMsg{MsgID: msg.MsgID, IsOk: false, Msg: fmt.Sprintf("Duplicated client, Client [%s] already connected", msg.Hi.ClientID), Timestamp: &now, ErrCode: AckErrDuplicatedClient}})
 			time.Sleep(2 * time.Second)
-			sess.closeGrpc()
+			rejected := &ClientInfo{ClientID: msg.Hi.ClientID, ClientName: msg.Hi.ClientName, ClientVersion: msg.Hi.ClientVersion}
+			globals.sessionStore.BroadcastPresence(sess, rejected, PresenceReject, CloseReasonDuplicate)
+			sess.closeGrpc(CloseReasonDuplicate)
 			return
 		}
+		if reason := checkService(msg.Hi.ServiceName, msg.Hi.ClientID); reason != "" {
+			logger.Warn(fmt.Sprintf("[Service Denied] Client '%s': %s, this connection will be dropped!", msg.Hi.ClientID, reason), zap.String("session", sess.sid))
+			sess.queueAck(msg.MsgID, false, AckErrServiceDenied, fmt.Sprintf("Service denied, %s", reason))
+			time.Sleep(2 * time.Second)
+			sess.closeGrpc(CloseReasonService)
+			return
+		}
+		if msg.Hi.ServiceName != "" {
+			globals.sessionStore.AddService(msg.Hi.ServiceName)
+		}
+		//会话可按ClientID查找前标记为重放中，避免定时重传与重放同时发送同一消息
+		sess.setReplaying(true)
 		sess.clientInfo.ClientID = msg.Hi.ClientID
 		sess.clientInfo.ClientName = msg.Hi.ClientName
 		sess.clientInfo.ClientVersion = msg.Hi.ClientVersion
 		sess.clientInfo.ClientDescription = msg.Hi.ClientDescription
 		sess.clientInfo.AllowedCommandIDs = msg.Hi.AllowedCommandIDs
+		sess.clientInfo.SubscribePresence = msg.Hi.SubscribePresence
+		sess.clientInfo.ServiceName = msg.Hi.ServiceName
 		sess.queueOut(&DMClientMsg{Ack: &DMAckMsg{MsgID: msg.MsgID, IsOk: true, Msg: "OK", Timestamp: &now}})
+		globals.sessionStore.BroadcastPresence(sess, &sess.clientInfo, PresenceJoin, "")
+		//发送目标离线期间存储的消息
+		ReplayPendingMsg(sess)
 	case msg.Leave != nil:
-		sess.closeGrpc()
+		sess.closeGrpc(CloseReasonLeave)
 
 	case msg.Req != nil:
-		reqReplyMsgID, _ := globals.sessionStore.uidGen.NewMsgUid()
-		replyReqMsg := &DMClientMsg{
-			Req: &DMClientReq{
-				ReqID:     msg.Req.ReqID,
-				From:      msg.Req.From,
-				To:        msg.Req.To,
-				CommandID: msg.Req.CommandID,
-				Timestamp: msg.Req.Timestamp,
-			},
-			MsgID: reqReplyMsgID,
-		}
-
-		//查找发送到的目标
-		reqToSess := globals.sessionStore.GetByClientID(msg.Req.To)
-		if reqToSess != nil {
+		//路由访问控制，拒绝的请求不存储也不转发
+		if !checkACL(msg.Req, msg.Req.To, sess.sid) {
+			sess.queueAck(msg.MsgID, false, AckErrAccessDenied, fmt.Sprintf("Access denied, [%s] is not allowed to send command [%d] to [%s]", msg.Req.From, msg.Req.CommandID, msg.Req.To))
+			return
+		}
+		if isBroadcast(msg.Req) {
+			sess.broadcastReq(msg)
+			return
+		}
+		replyReqMsg := newRouteReqMsg(msg.Req)
+
+		//查找发送到的目标客户端或服务成员
+		reqToSess := resolveTarget(msg.Req)
+		if reqToSess != nil && !checkACL(msg.Req, reqToSess.clientInfo.ClientID, sess.sid) {
+			//请求解析到的客户端同样须通过访问控制
+			sess.queueAck(msg.MsgID, false, AckErrAccessDenied, fmt.Sprintf("Access denied, [%s] is not allowed to send command [%d] to [%s]", msg.Req.From, msg.Req.CommandID, reqToSess.clientInfo.ClientID))
+		} else if reqToSess != nil && !reqToSess.isCommandAllowed(msg.Req.CommandID) {
+			//目标未声明该命令，拒绝请求，不转发也不存储
+			logger.Warn(fmt.Sprintf("[Command Not Allowed] Client [%s] does not accept command [%d] sent by [%s]", msg.Req.To, msg.Req.CommandID, msg.Req.From), zap.String("ReqID", msg.Req.ReqID))
+			sess.queueAck(msg.MsgID, false, AckErrCommandNotAllowed, fmt.Sprintf("Command Not Allowed, target [%s] does not accept command [%d]", msg.Req.To, msg.Req.CommandID))
+		} else if reqToSess != nil {
 			//存储消息
-			store.MsgObj.InsertReq(&types.ReqReceived{
-				Version:   types.DefaultMsgVersion,
-				MsgID:     replyReqMsg.MsgID,
-				ReqID:     replyReqMsg.Req.ReqID,
-				From:      replyReqMsg.Req.From,
-				To:        replyReqMsg.Req.To,
-				Content:   GetJsonString(replyReqMsg),
-				ExpiresAt: types.GetExpiresTime(globals.configs.MessageExpireMinuteInterval),
-				Retries:   0,
-				Status:    types.StatusQueued,
-			})
-
-			reqToSess.queueOut(replyReqMsg)
-
-			reqAckMsgID, _ := globals.sessionStore.uidGen.NewMsgUid()
-			now := types.TimeNow()
-			sess.queueOut(&DMClientMsg{
-				Ack: &DMAckMsg{
-					MsgID:     msg.MsgID,
-					IsOk:      true,
-					Msg:       "OK",
-					Timestamp: &now,
-				},
-				MsgID: reqAckMsgID,
-			})
+			saveReq(replyReqMsg, reqToSess.clientInfo.ClientID, types.StatusQueued)
+			globals.reqTracker.Add(msg.Req, replyReqMsg.MsgID, reqToSess.clientInfo.ClientID)
+
+			reqToSess.deliver(replyReqMsg)
+			sess.queueAck(msg.MsgID, true, AckErrNone, "OK")
 		} else {
-			store.MsgObj.InsertReq(&types.ReqReceived{
-				Version:   types.DefaultMsgVersion,
-				MsgID:     replyReqMsg.MsgID,
-				ReqID:     replyReqMsg.Req.ReqID,
-				From:      replyReqMsg.Req.From,
-				To:        replyReqMsg.Req.To,
-				Content:   GetJsonString(replyReqMsg),
-				ExpiresAt: types.GetExpiresTime(globals.configs.MessageExpireMinuteInterval),
-				Retries:   0,
-				Status:    types.StatusFailed,
-			})
-
-			reqAckMsgID, _ := globals.sessionStore.uidGen.NewMsgUid()
-			now := types.TimeNow()
-			sess.queueOut(&DMClientMsg{
-				Ack: &DMAckMsg{
-					MsgID:     msg.MsgID,
-					IsOk:      false,
-					Msg:       fmt.Sprintf("Target Not Found, Please Online target [%s] first", msg.Req.To),
-					Timestamp: &now,
-				},
-				MsgID: reqAckMsgID,
-			})
+			//目标离线，存储消息，待目标(或服务的任一成员)上线后发送
+			saveReq(replyReqMsg, msg.Req.To, types.StatusPending)
+			globals.reqTracker.Add(msg.Req, replyReqMsg.MsgID, msg.Req.To)
+			sess.queueAck(msg.MsgID, true, AckQueued, fmt.Sprintf("Queued, target [%s] is offline, message will be delivered when it comes online", msg.Req.To))
 		}
 
 	case msg.Resp != nil:
-		respReplyMsgID, _ := globals.sessionStore.uidGen.NewMsgUid()
-		replyRespMsg := &DMClientMsg{
-			Resp: &DMClientResp{
-				RespID:    msg.Resp.RespID,
-				From:      msg.Resp.From,
-				To:        msg.Resp.To,
-				Content:   msg.Resp.Content,
-				ErrCode:   msg.Resp.ErrCode,
-				ErrMsg:    msg.Resp.ErrMsg,
-				Timestamp: msg.Resp.Timestamp,
-			},
-			MsgID: respReplyMsgID,
-		}
-
-		//查找接收结果的目标
-		respToSess := globals.sessionStore.GetByClientID(msg.Resp.To)
-		if respToSess != nil {
-
-			store.MsgObj.InsertResp(&types.RespReceived{
-				Version:   types.DefaultMsgVersion,
-				MsgID:     replyRespMsg.MsgID,
-				RespID:    replyRespMsg.Resp.RespID,
-				From:      replyRespMsg.Resp.From,
-				To:        replyRespMsg.Resp.To,
-				Content:   GetJsonString(replyRespMsg),
-				ExpiresAt: types.GetExpiresTime(globals.configs.MessageExpireMinuteInterval),
-				Retries:   0,
-				Status:    types.StatusQueued,
-			})
-
-			respToSess.queueOut(replyRespMsg)
-
-			respAckMsgID, _ := globals.sessionStore.uidGen.NewMsgUid()
-			now := types.TimeNow()
-			sess.queueOut(&DMClientMsg{
-				Ack: &DMAckMsg{
-					MsgID:     msg.MsgID,
-					IsOk:      true,
-					Msg:       "OK",
-					Timestamp: &now,
-				},
-				MsgID: respAckMsgID,
-			})
-		} else {
+		//同步调用(Call)等待的响应直接交给调用方，无需转发
+		if globals.callStore.Deliver(msg.Resp) {
+			sess.queueAck(msg.MsgID, true, AckErrNone, "OK")
+			return
+		}
 
-			store.MsgObj.InsertResp(&types.RespReceived{
-				Version:   types.DefaultMsgVersion,
-				MsgID:     replyRespMsg.MsgID,
-				RespID:    replyRespMsg.Resp.RespID,
-				From:      replyRespMsg.Resp.From,
-				To:        replyRespMsg.Resp.To,
-				Content:   GetJsonString(replyRespMsg),
-				ExpiresAt: types.GetExpiresTime(globals.configs.MessageExpireMinuteInterval),
-				Retries:   0,
-				Status:    types.StatusFailed,
-			})
-
-			respAckMsgID, _ := globals.sessionStore.uidGen.NewMsgUid()
-			now := types.TimeNow()
-			sess.queueOut(&DMClientMsg{
-				Ack: &DMAckMsg{
-					MsgID:     msg.MsgID,
-					IsOk:      false,
-					Msg:       fmt.Sprintf("Target Not Found, Please Online target [%s] first", msg.Req.To),
-					Timestamp: &now,
-				},
-				MsgID: respAckMsgID,
-			})
+		//广播请求的响应汇总后再发送给请求方
+		if reqMsgID, ok := globals.broadcastStore.Deliver(msg.Resp); ok {
+			if reqMsgID != "" {
+				ackRespondedReq(reqMsgID, sess.clientInfo.ClientID)
+			}
+			sess.queueAck(msg.MsgID, true, AckErrNone, "OK")
+			return
+		}
+
+		//响应须对应一个发送给该客户端且仍在等待响应的请求
+		req, errCode, errMsg := globals.reqTracker.Respond(msg.Resp, sess.clientInfo.ClientID)
+		if errCode != AckErrNone {
+			logger.Warn(fmt.Sprintf("[Response Rejected] %s", errMsg), zap.String("RespID", msg.Resp.RespID), zap.String("session", sess.sid))
+			sess.queueAck(msg.MsgID, false, errCode, errMsg)
+			return
+		}
+		ackRespondedReq(req.msgID, sess.clientInfo.ClientID)
+		logger.Debug(fmt.Sprintf("[Response] Client [%s] responded to request [%s] of [%s] in %s", sess.clientInfo.ClientID, msg.Resp.RespID, msg.Resp.To, types.TimeNow().Sub(req.sentAt)))
+
+		if sendResp(msg.Resp, req) {
+			sess.queueAck(msg.MsgID, true, AckErrNone, "OK")
+		} else {
+			sess.queueAck(msg.MsgID, true, AckQueued, fmt.Sprintf("Queued, target [%s] is offline, message will be delivered when it comes online", msg.Resp.To))
 		}
 	case msg.Ack != nil:
-		logger.Debug(fmt.Sprintf("Client Ack Msg: %v", msg.Ack))
+		ackMsgStatus(sess.clientInfo.ClientID, msg.Ack)
+
+	case msg.Subscribe != nil:
+		if !validTopic(msg.Subscribe.Topic, true) {
+			sess.queueAck(msg.MsgID, false, AckErrInvalidTopic, fmt.Sprintf("Invalid topic pattern [%s]", msg.Subscribe.Topic))
+			return
+		}
+		sess.subscribe(msg.Subscribe.Topic)
+		sess.queueAck(msg.MsgID, true, AckErrNone, "OK")
+
+	case msg.Unsubscribe != nil:
+		sess.unsubscribe(msg.Unsubscribe.Topic)
+		sess.queueAck(msg.MsgID, true, AckErrNone, "OK")
+
+	case msg.Publish != nil:
+		if !validTopic(msg.Publish.Topic, false) {
+			sess.queueAck(msg.MsgID, false, AckErrInvalidTopic, fmt.Sprintf("Invalid topic [%s]", msg.Publish.Topic))
+			return
+		}
+		//事件不存储，仅发送给当前在线的订阅者
+		now := types.TimeNow()
+		publish := &DMPublish{Topic: msg.Publish.Topic, From: sess.clientInfo.ClientID, Content: msg.Publish.Content, Timestamp: msg.Publish.Timestamp}
+		if publish.Timestamp == nil {
+			publish.Timestamp = &now
+		}
+		count := globals.sessionStore.Publish(sess, publish)
+		sess.queueAck(msg.MsgID, true, AckErrNone, fmt.Sprintf("Published to %d subscribers", count))
+
+	case msg.Ping != nil:
+		//会话活跃时间已在收到消息时更新，此处仅需回复Pong
+		pongMsgID, _ := globals.sessionStore.uidGen.NewMsgUid()
+		now := types.TimeNow()
+		sess.queueOut(&DMClientMsg{Pong: &DMPong{PingTimestamp: msg.Ping.Timestamp, Timestamp: &now}, MsgID: pongMsgID})
+
+	case msg.Pong != nil:
+		if msg.Pong.PingTimestamp != nil {
+			logger.Debug(fmt.Sprintf("Client Pong, round trip: %s", types.TimeNow().Sub(*msg.Pong.PingTimestamp)), zap.String("session", sess.sid))
+		}
 
 	}
 
 }
 
+// checkProtocol validates the message against the state of the session: messages other than Hi, Leave
+// and Ping/Pong are accepted only once Hi is completed, and the sender of a request or a response must be
+// the client of the session. An empty sender is set to the client of the session.
+// Returns the reason if the message is rejected.
+func (sess *Session) checkProtocol(msg *DMClientMsg) string {
+	if msg.Hi != nil || msg.Leave != nil || msg.Ping != nil || msg.Pong != nil {
+		return ""
+	}
+	clientID := sess.clientInfo.ClientID
+	if clientID == "" {
+		return "Hi is required before any other message"
+	}
+
+	var from *string
+	switch {
+	case msg.Req != nil:
+		from = &msg.Req.From
+	case msg.Resp != nil:
+		from = &msg.Resp.From
+	default:
+		return ""
+	}
+	if !bindFrom(from, clientID) {
+		return fmt.Sprintf("From [%s] does not match the ClientID [%s] of the session", *from, clientID)
+	}
+	return ""
+}
+
+// bindFrom binds the sender of a message to the client clientID it was received from: an empty sender
+// is set to clientID, any other sender must be clientID. Returns false if the sender does not match.
+func bindFrom(from *string, clientID string) bool {
+	if *from == "" {
+		*from = clientID
+	}
+	return *from == clientID
+}
+
+// Call routes a single request to its target and blocks until the target responds or the deadline passes.
+func (*grpcNodeServer) Call(ctx context.Context, req *golazy.ClientReq) (*golazy.ClientResp, error) {
+	clientID, err := authenticateCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in := PbDeserialize(&golazy.ClientMsg{Message: &golazy.ClientMsg_Req{Req: req}}).Req
+	//与流式连接相同，请求的发送方须为已认证的客户端，之后的访问控制及限流均基于该身份
+	if clientID != "" {
+		if !bindFrom(&in.From, clientID) {
+			logger.Warn(fmt.Sprintf("[Identity Mismatch] Call From [%s] does not match the authenticated ClientID [%s]", in.From, clientID), zap.String("ReqID", in.ReqID), zap.String("remote", peerAddr(ctx)))
+			return nil, status.Errorf(codes.PermissionDenied, "Identity mismatch, From [%s] does not match the authenticated ClientID [%s]", in.From, clientID)
+		}
+	}
+	if in.ReqID == "" {
+		in.ReqID, _ = globals.sessionStore.uidGen.NewMsgUid()
+	}
+
+	//调用方未设置超时时间时使用默认超时时间
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(globals.configs.CallTimeoutSecond)*time.Second)
+		defer cancel()
+	}
+	//请求设置了超时时间时，以其作为调用的超时时间
+	if in.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(in.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	//按已认证的客户端限流，未认证时按调用方地址限流，伪造From无法绕过限制
+	limitKey := clientID
+	if limitKey == "" {
+		limitKey = ipLimitKey(peerHost(ctx))
+	}
+	if retryAfter, scope := globals.rateLimiter.Allow(nil, limitKey); retryAfter > 0 {
+		logger.Debug(fmt.Sprintf("[Rate Limited] Caller [%s] exceeded the %s rate limit", limitKey, scope), zap.String("ReqID", in.ReqID))
+		return nil, status.Errorf(codes.ResourceExhausted, "Rate limited, %s rate limit exceeded, retry after %s", scope, retryAfter)
+	}
+
+	if !checkACL(in, in.To, "call") {
+		return nil, status.Errorf(codes.PermissionDenied, "Access denied, [%s] is not allowed to send command [%d] to [%s]", in.From, in.CommandID, in.To)
+	}
+
+	if isBroadcast(in) {
+		return callBroadcast(ctx, in)
+	}
+
+	reqToSess := resolveTarget(in)
+	if reqToSess == nil {
+		return nil, status.Errorf(codes.NotFound, "Target Not Found, Please Online target [%s] first", in.To)
+	}
+	if !checkACL(in, reqToSess.clientInfo.ClientID, "call") {
+		return nil, status.Errorf(codes.PermissionDenied, "Access denied, [%s] is not allowed to send command [%d] to [%s]", in.From, in.CommandID, reqToSess.clientInfo.ClientID)
+	}
+	if !reqToSess.isCommandAllowed(in.CommandID) {
+		return nil, status.Errorf(codes.PermissionDenied, "Command Not Allowed, target [%s] does not accept command [%d]", in.To, in.CommandID)
+	}
+
+	respCh, ok := globals.callStore.Add(in.From, in.ReqID, reqToSess.clientInfo.ClientID)
+	if !ok {
+		return nil, status.Errorf(codes.AlreadyExists, "Duplicated call, request [%s] is already waiting for response", in.ReqID)
+	}
+	defer globals.callStore.Remove(in.From, in.ReqID)
+
+	reqMsg := newRouteReqMsg(in)
+	saveReq(reqMsg, reqToSess.clientInfo.ClientID, types.StatusQueued)
+	reqToSess.deliver(reqMsg)
+
+	select {
+	case resp := <-respCh:
+		ackRespondedReq(reqMsg.MsgID, resp.From)
+		return PBClientRespSerialize(resp).Resp, nil
+	case <-ctx.Done():
+		//调用方不再等待响应，不再重发请求
+		expireReq(reqMsg.MsgID)
+		if ctx.Err() == context.DeadlineExceeded {
+			logger.Warn(fmt.Sprintf("[Call Timeout] Target [%s] did not respond to request [%s] in time", in.To, in.ReqID))
+			return nil, status.Errorf(codes.DeadlineExceeded, "Call timeout, target [%s] did not respond to request [%s] in time", in.To, in.ReqID)
+		}
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// callBroadcast delivers a broadcast request and waits for the responses of its targets until the quorum
+// is reached, the broadcast timeout or the deadline of the call passes. The targets which did not respond are reported in the aggregated response.
+func callBroadcast(ctx context.Context, in *DMClientReq) (*golazy.ClientResp, error) {
+	targets := broadcastTargets(in)
+	if len(targets) == 0 {
+		return nil, status.Errorf(codes.NotFound, "Target Not Found, no online client matches [%s]", in.To)
+	}
+	b := globals.broadcastStore.Start(in, targets)
+	if b == nil {
+		return nil, status.Errorf(codes.AlreadyExists, "Duplicated call, request [%s] is already waiting for response", in.ReqID)
+	}
+
+	select {
+	case <-b.done:
+	case <-time.After(broadcastTimeout(in)):
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			globals.broadcastStore.Finish(b)
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	return PBClientRespSerialize(globals.broadcastStore.Finish(b)).Resp, nil
+}
+
+// ListClients returns the clients connected to the bus, optionally filtered by name prefix and
+// by commands which all of the returned clients accept.
+func (*grpcNodeServer) ListClients(ctx context.Context, req *golazy.ListClientsReq) (*golazy.ListClientsResp, error) {
+	if _, err := authenticateCall(ctx); err != nil {
+		return nil, err
+	}
+	resp := &golazy.ListClientsResp{}
+	for _, sess := range globals.sessionStore.GetClients() {
+		if !strings.HasPrefix(sess.clientInfo.ClientName, req.GetNamePrefix()) {
+			continue
+		}
+		allowed := true
+		for _, commandID := range req.GetCommandIDs() {
+			if !sess.isCommandAllowed(commandID) {
+				allowed = false
+				break
+			}
+		}
+		if !allowed {
+			continue
+		}
+		resp.Clients = append(resp.Clients, &golazy.ClientInfo{
+			ClientID:          sess.clientInfo.ClientID,
+			ClientName:        sess.clientInfo.ClientName,
+			ClientVersion:     sess.clientInfo.ClientVersion,
+			ClientDescription: sess.clientInfo.ClientDescription,
+			AllowedCommandIDs: sess.clientInfo.AllowedCommandIDs,
+			RemoteAddr:        sess.remoteAddr,
+			ConnectedAt:       timeToInt64(&sess.connectedAt),
+			ServiceName:       sess.clientInfo.ServiceName,
+		})
+	}
+	return resp, nil
+}
+
+// newRouteReqMsg wraps a request received from a client into a new message for its target.
+func newRouteReqMsg(req *DMClientReq) *DMClientMsg {
+	msgID, _ := globals.sessionStore.uidGen.NewMsgUid()
+	return &DMClientMsg{
+		Req: &DMClientReq{
+			ReqID:     req.ReqID,
+			From:      req.From,
+			To:        req.To,
+			CommandID: req.CommandID,
+			Content:   req.Content,
+			Timestamp: req.Timestamp,
+			RouteKey:  req.RouteKey,
+			Broadcast: req.Broadcast,
+			Quorum:    req.Quorum,
+			TimeoutMs: req.TimeoutMs,
+		},
+		MsgID: msgID,
+	}
+}
+
+// newRouteRespMsg wraps a response received from a client into a new message for its target.
+func newRouteRespMsg(resp *DMClientResp) *DMClientMsg {
+	msgID, _ := globals.sessionStore.uidGen.NewMsgUid()
+	return &DMClientMsg{
+		Resp: &DMClientResp{
+			RespID:    resp.RespID,
+			From:      resp.From,
+			To:        resp.To,
+			Content:   resp.Content,
+			ErrCode:   resp.ErrCode,
+			ErrMsg:    resp.ErrMsg,
+			Timestamp: resp.Timestamp,
+			Results:   resp.Results,
+		},
+		MsgID: msgID,
+	}
+}
+
+// broadcastReq delivers a broadcast request to its targets, then sends the aggregated response to the
+// sender once the quorum is reached or the broadcast timeout passes.
+func (sess *Session) broadcastReq(msg *DMClientMsg) {
+	targets := broadcastTargets(msg.Req)
+	if len(targets) == 0 {
+		sess.queueAck(msg.MsgID, false, AckErrTargetNotFound, fmt.Sprintf("Target Not Found, no online client matches [%s]", msg.Req.To))
+		return
+	}
+	b := globals.broadcastStore.Start(msg.Req, targets)
+	if b == nil {
+		sess.queueAck(msg.MsgID, false, AckErrDuplicatedRequest, fmt.Sprintf("Duplicated request, request [%s] is already waiting for responses", msg.Req.ReqID))
+		return
+	}
+	sess.queueAck(msg.MsgID, true, AckErrNone, fmt.Sprintf("Broadcast to %d targets", len(targets)))
+
+	go func() {
+		select {
+		case <-b.done:
+		case <-time.After(broadcastTimeout(msg.Req)):
+		}
+		sendResp(globals.broadcastStore.Finish(b), nil)
+	}()
+}
+
+// sendResp routes a response to its target, or stores it until the target comes online. 'req' is the
+// request answered by the response, nil for the responses generated by the server.
+// Returns false if the target is offline.
+func sendResp(resp *DMClientResp, req *trackedReq) bool {
+	respMsg := newRouteRespMsg(resp)
+
+	//查找接收结果的目标
+	respToSess := globals.sessionStore.GetByClientID(resp.To)
+	if respToSess == nil {
+		//目标离线，存储消息，待目标上线后发送
+		saveResp(respMsg, req, types.StatusPending)
+		return false
+	}
+	saveResp(respMsg, req, types.StatusQueued)
+	respToSess.deliver(respMsg)
+	return true
+}
+
+// resolveTarget finds the session a request is sent to: the client with ClientID req.To if it is
+// online, otherwise a member of the service named req.To. Returns nil if neither is online.
+func resolveTarget(req *DMClientReq) *Session {
+	if sess := globals.sessionStore.GetByClientID(req.To); sess != nil {
+		return sess
+	}
+	return globals.sessionStore.PickServiceMember(req.To, req.RouteKey, req.From, req.CommandID)
+}
+
+// saveReq persists a routed request message with the given status. 'to' is the ClientID of the
+// session the request is delivered to, or the target of the request if it is offline.
+func saveReq(msg *DMClientMsg, to string, status string) {
+	store.MsgObj.InsertReq(&types.ReqReceived{
+		Version:   types.DefaultMsgVersion,
+		MsgID:     msg.MsgID,
+		ReqID:     msg.Req.ReqID,
+		From:      msg.Req.From,
+		To:        to,
+		Content:   GetJsonString(msg),
+		ExpiresAt: types.GetExpiresTime(globals.configs.MessageExpireMinuteInterval),
+		Retries:   0,
+		Status:    status,
+		Deadline:  reqDeadline(msg.Req),
+		ToService: globals.sessionStore.IsService(msg.Req.To),
+	})
+}
+
+// saveResp persists a routed response message with the given status, along with the request it answers.
+func saveResp(msg *DMClientMsg, req *trackedReq, status string) {
+	resp := &types.RespReceived{
+		Version:   types.DefaultMsgVersion,
+		MsgID:     msg.MsgID,
+		RespID:    msg.Resp.RespID,
+		From:      msg.Resp.From,
+		To:        msg.Resp.To,
+		Content:   GetJsonString(msg),
+		ExpiresAt: types.GetExpiresTime(globals.configs.MessageExpireMinuteInterval),
+		Retries:   0,
+		Status:    status,
+	}
+	if req != nil {
+		resp.ReqMsgID = req.msgID
+		resp.LatencyMs = types.TimeNow().Sub(req.sentAt).Nanoseconds() / int64(time.Millisecond)
+	}
+	store.MsgObj.InsertResp(resp)
+}
+
+// queueAck replies to the client's message msgID.
+func (sess *Session) queueAck(msgID string, isOk bool, errCode int32, text string) {
+	sess.queueAckMsg(&DMAckMsg{MsgID: msgID, IsOk: isOk, Msg: text, ErrCode: errCode})
+}
+
+// queueRetryAck rejects the client's message msgID, which may be sent again after retryAfter.
+func (sess *Session) queueRetryAck(msgID string, errCode int32, text string, retryAfter time.Duration) {
+	retryAfterMs := int64(retryAfter / time.Millisecond)
+	if retryAfter%time.Millisecond != 0 {
+		retryAfterMs++
+	}
+	sess.queueAckMsg(&DMAckMsg{MsgID: msgID, IsOk: false, Msg: text, ErrCode: errCode, RetryAfterMs: retryAfterMs})
+}
+
+func (sess *Session) queueAckMsg(ack *DMAckMsg) {
+	ackMsgID, _ := globals.sessionStore.uidGen.NewMsgUid()
+	now := types.TimeNow()
+	ack.Timestamp = &now
+	sess.queueOut(&DMClientMsg{Ack: ack, MsgID: ackMsgID})
+}
+
+// deliver queues a stored Req or Resp message for the client. If the queue is full the message
+// is marked as failed in the store, to be sent again later.
+func (sess *Session) deliver(msg *DMClientMsg) {
+	if msg.Req != nil {
+		//先计数，客户端可能在入队后立即响应
+		sess.addRequest(msg.MsgID)
+	}
+	if !sess.queueOut(msg) {
+		if msg.Req != nil {
+			sess.doneRequest(msg.MsgID)
+		}
+		updateMsgStatus(newMsgSendStatus(PbSerialize(msg), false, "send queue full or session closed"))
+	}
+}
+
+// deliverWait is deliver waiting for room in the send queue, used to send the stored messages in order.
+// Returns false if the session is closed.
+func (sess *Session) deliverWait(msg *DMClientMsg) bool {
+	if msg.Req != nil {
+		sess.addRequest(msg.MsgID)
+	}
+	if !sess.queueOutWait(msg) {
+		if msg.Req != nil {
+			sess.doneRequest(msg.MsgID)
+		}
+		updateMsgStatus(newMsgSendStatus(PbSerialize(msg), false, "session closed"))
+		return false
+	}
+	return true
+}
+
+// addRequest counts the request delivered by the message msgID as waiting for the response of the client.
+// A request sent again is counted once.
+func (sess *Session) addRequest(msgID string) {
+	sess.lock.Lock()
+	defer sess.lock.Unlock()
+
+	if sess.outstanding == nil {
+		sess.outstanding = make(map[string]struct{})
+	}
+	sess.outstanding[msgID] = struct{}{}
+}
+
+// doneRequest records that the request delivered by the message msgID was responded, or is no longer waited for.
+func (sess *Session) doneRequest(msgID string) {
+	sess.lock.Lock()
+	defer sess.lock.Unlock()
+
+	delete(sess.outstanding, msgID)
+}
+
+// outstandingRequests returns the number of requests delivered to the client and not responded yet.
+func (sess *Session) outstandingRequests() int {
+	sess.lock.Lock()
+	defer sess.lock.Unlock()
+
+	return len(sess.outstanding)
+}
+
+// releaseRequest stops counting the request delivered by the message msgID to the client clientID, if it is online.
+func releaseRequest(clientID string, msgID string) {
+	if sess := globals.sessionStore.GetByClientID(clientID); sess != nil {
+		sess.doneRequest(msgID)
+	}
+}
+
 func (sess *Session) writeGrpcLoop() {
 
+	closeReason := CloseReasonStreamError
 	defer func() {
-		sess.closeGrpc() // exit MessageLoop
+		//未能发送的消息标记为失败，以便重连或重启后重新发送
+		sess.drainQueue(false)
+		sess.closeGrpc(closeReason) // exit MessageLoop
+		globals.sessionStore.writers.Done()
 	}()
 
 	for {
@@ -254,70 +709,278 @@ func (sess *Session) writeGrpcLoop() {
 				// channel closed
 				return
 			}
-			statusType := "unknown"
 			m := msg.(*golazy.ClientMsg)
-			if n := m.GetReq(); n != nil {
-				statusType = "req"
-			} else if n := m.GetResp(); n != nil {
-				statusType = "resp"
-			}
-
 			if err := grpcWrite(sess, msg); err != nil {
 				logger.Error("grpc: write", zap.String("session", sess.sid), zap.Error(err))
-				sess.msgSendStatus <- MsgSendStatus{MsgID: m.MsgID, MsgType: statusType, IsOk: false}
+				updateMsgStatus(newMsgSendStatus(m, false, err.Error()))
 				return
 			}
-			sess.msgSendStatus <- MsgSendStatus{MsgID: m.MsgID, MsgType: statusType, IsOk: true}
+			sess.msgSendStatus <- newMsgSendStatus(m, true, "")
 
 		case msg := <-sess.stop:
-			// Shutdown requested, don't care if the message is delivered
+			// Shutdown requested, flush queued messages first, don't care if the last message is delivered
+			sess.drainQueue(true)
 			if msg != nil {
 				err := grpcWrite(sess, msg)
 				logger.Error("grpc: stop", zap.String("session", sess.sid), zap.Error(err))
-				return
 			}
+			sess.lock.Lock()
+			closeReason = sess.stopReason
+			sess.lock.Unlock()
+			return
+
+		case <-sess.done:
+			// Session closed by the read side
 			return
 
 		case msgStatus := <-sess.msgSendStatus:
-			switch msgStatus.MsgType {
-			case "req":
-				msg, err := store.MsgObj.GetReqByMsgID(msgStatus.MsgID)
-				if err != nil {
-					logger.Error("GetReqByMsgID failed", zap.String("MsgID", msgStatus.MsgID), zap.Error(err))
-				} else {
-					if msgStatus.IsOk {
-						msg.Status = types.StatusSucceeded
-					} else {
-						msg.Status = types.StatusFailed
-					}
-
-					err = store.MsgObj.UpdateReq(msg)
-					if err != nil {
-						logger.Error("UpdateReq failed", zap.String("MsgID", msgStatus.MsgID), zap.Error(err))
-					}
-				}
-			case "resp":
-				msg, err := store.MsgObj.GetRespByMsgID(msgStatus.MsgID)
-				if err != nil {
-					logger.Error("GetRespByMsgID failed", zap.String("MsgID", msgStatus.MsgID), zap.Error(err))
+			updateMsgStatus(msgStatus)
+
+		}
+	}
+}
+
+// drainQueue empties the outbound queue of the session. If flush is true it tries to send the
+// queued messages, otherwise, or once sending fails, they are marked as failed in the store.
+func (sess *Session) drainQueue(flush bool) {
+	for {
+		select {
+		case msg, ok := <-sess.send:
+			if !ok {
+				return
+			}
+			m := msg.(*golazy.ClientMsg)
+			sent := false
+			reason := "session closed before the message was sent"
+			if flush {
+				if err := grpcWrite(sess, msg); err != nil {
+					logger.Error("grpc: flush", zap.String("session", sess.sid), zap.Error(err))
+					flush = false
+					reason = err.Error()
 				} else {
-					if msgStatus.IsOk {
-						msg.Status = types.StatusSucceeded
-					} else {
-						msg.Status = types.StatusFailed
-					}
-
-					err = store.MsgObj.UpdateResp(msg)
-					if err != nil {
-						logger.Error("UpdateResp failed", zap.String("MsgID", msgStatus.MsgID), zap.Error(err))
-					}
+					sent = true
+					reason = ""
 				}
 			}
+			updateMsgStatus(newMsgSendStatus(m, sent, reason))
+		case msgStatus := <-sess.msgSendStatus:
+			updateMsgStatus(msgStatus)
+		default:
+			return
+		}
+	}
+}
+
+func newMsgSendStatus(m *golazy.ClientMsg, isOk bool, reason string) MsgSendStatus {
+	statusType := "unknown"
+	if n := m.GetReq(); n != nil {
+		statusType = "req"
+	} else if n := m.GetResp(); n != nil {
+		statusType = "resp"
+	}
+	return MsgSendStatus{MsgID: m.MsgID, MsgType: statusType, IsOk: isOk, Reason: reason}
+}
+
+// updateMsgStatus saves the result of sending a message, or the client's ack of it, to the store.
+func updateMsgStatus(msgStatus MsgSendStatus) {
+	switch msgStatus.MsgType {
+	case "req":
+		msg, err := store.MsgObj.GetReqByMsgID(msgStatus.MsgID)
+		if err != nil {
+			logger.Error("GetReqByMsgID failed", zap.String("MsgID", msgStatus.MsgID), zap.Error(err))
+			return
+		}
+		applyReqStatus(msg, msgStatus)
+	case "resp":
+		msg, err := store.MsgObj.GetRespByMsgID(msgStatus.MsgID)
+		if err != nil {
+			logger.Error("GetRespByMsgID failed", zap.String("MsgID", msgStatus.MsgID), zap.Error(err))
+			return
+		}
+		applyRespStatus(msg, msgStatus)
+	}
+}
+
+// applyReqStatus saves the result of sending the stored request msg, or the client's ack of it.
+func applyReqStatus(msg *types.ReqReceived, msgStatus MsgSendStatus) {
+	saveReqStatus(msg, func(msg *types.ReqReceived) bool {
+		if msgStatus.AckFrom != "" && msgStatus.AckFrom != msg.To {
+			logger.Warn(fmt.Sprintf("[Ack Ignored] Client [%s] acked request [%s] sent to [%s]", msgStatus.AckFrom, msg.MsgID, msg.To))
+			return false
+		}
+		//客户端的确认可能先于写入结果处理，已确认或已响应的请求不再改回已发送或发送失败
+		if msg.Status == types.StatusAcked || msg.Status == types.StatusResponded {
+			return false
+		}
+		if msgStatus.IsOk && msgStatus.AckFrom != "" {
+			msg.Status = types.StatusAcked
+		} else if msgStatus.IsOk {
+			msg.Status = types.StatusSent
+			msg.NextAttemptAt = types.TimeNow().Add(time.Duration(globals.configs.AckTimeoutSecond) * time.Second)
+		} else {
+			msg.LastError = truncateError(msgStatus.Reason)
+			if msg.Retries >= globals.configs.MaxRetryCount {
+				//重传次数已达上限，转为死信消息
+				msg.Status = types.StatusDead
+				logger.Warn(fmt.Sprintf("[Dead Letter] Request [%s] from [%s] to [%s] gave up after %d retries: %s", msg.MsgID, msg.From, msg.To, msg.Retries, msg.LastError))
+				releaseRequest(msg.To, msg.MsgID)
+			} else {
+				msg.Status = types.StatusFailed
+				msg.NextAttemptAt = nextAttemptTime(msg.Retries)
+			}
+		}
+		return true
+	})
+}
+
+// applyRespStatus saves the result of sending the stored response msg, or the client's ack of it.
+func applyRespStatus(msg *types.RespReceived, msgStatus MsgSendStatus) {
+	saveRespStatus(msg, func(msg *types.RespReceived) bool {
+		if msgStatus.AckFrom != "" && msgStatus.AckFrom != msg.To {
+			logger.Warn(fmt.Sprintf("[Ack Ignored] Client [%s] acked response [%s] sent to [%s]", msgStatus.AckFrom, msg.MsgID, msg.To))
+			return false
+		}
+		//客户端的确认可能先于写入结果处理，已确认的消息不再改回已发送或发送失败
+		if msg.Status == types.StatusAcked {
+			return false
+		}
+		if msgStatus.IsOk && msgStatus.AckFrom != "" {
+			msg.Status = types.StatusAcked
+		} else if msgStatus.IsOk {
+			msg.Status = types.StatusSent
+			msg.NextAttemptAt = types.TimeNow().Add(time.Duration(globals.configs.AckTimeoutSecond) * time.Second)
+		} else {
+			msg.LastError = truncateError(msgStatus.Reason)
+			if msg.Retries >= globals.configs.MaxRetryCount {
+				//重传次数已达上限，转为死信消息
+				msg.Status = types.StatusDead
+				logger.Warn(fmt.Sprintf("[Dead Letter] Response [%s] from [%s] to [%s] gave up after %d retries: %s", msg.MsgID, msg.From, msg.To, msg.Retries, msg.LastError))
+			} else {
+				msg.Status = types.StatusFailed
+				msg.NextAttemptAt = nextAttemptTime(msg.Retries)
+			}
+		}
+		return true
+	})
+}
+
+// saveReqStatus applies update to the stored request and saves it only if its status is still the one
+// it was read with, so that concurrent updates of the same request do not overwrite each other. On a
+// conflict the request is read again and update applied to it. update returns false to leave it unchanged.
+func saveReqStatus(req *types.ReqReceived, update func(req *types.ReqReceived) bool) {
+	for i := 0; i < maxStatusUpdateAttempts; i++ {
+		status := req.Status
+		if !update(req) {
+			return
+		}
+		ok, err := store.MsgObj.UpdateReqIf(req, status)
+		if err != nil {
+			logger.Error("UpdateReq failed", zap.String("MsgID", req.MsgID), zap.Error(err))
+			return
+		}
+		if ok {
+			return
+		}
+		if req, err = store.MsgObj.GetReqByMsgID(req.MsgID); err != nil {
+			logger.Error("GetReqByMsgID failed", zap.String("MsgID", req.MsgID), zap.Error(err))
+			return
+		}
+	}
+	logger.Warn(fmt.Sprintf("Request [%s] status not saved, it kept changing concurrently", req.MsgID))
+}
 
+// saveRespStatus applies update to the stored response and saves it only if its status is still the one
+// it was read with, see saveReqStatus.
+func saveRespStatus(resp *types.RespReceived, update func(resp *types.RespReceived) bool) {
+	for i := 0; i < maxStatusUpdateAttempts; i++ {
+		status := resp.Status
+		if !update(resp) {
+			return
+		}
+		ok, err := store.MsgObj.UpdateRespIf(resp, status)
+		if err != nil {
+			logger.Error("UpdateResp failed", zap.String("MsgID", resp.MsgID), zap.Error(err))
+			return
+		}
+		if ok {
+			return
+		}
+		if resp, err = store.MsgObj.GetRespByMsgID(resp.MsgID); err != nil {
+			logger.Error("GetRespByMsgID failed", zap.String("MsgID", resp.MsgID), zap.Error(err))
+			return
+		}
+	}
+	logger.Warn(fmt.Sprintf("Response [%s] status not saved, it kept changing concurrently", resp.MsgID))
+}
+
+// updateReqIf saves a request read by a scan of the store if its status is still 'status'. Returns false
+// if it was changed in the meantime, e.g. acked, or could not be saved.
+func updateReqIf(req *types.ReqReceived, status string) bool {
+	ok, err := store.MsgObj.UpdateReqIf(req, status)
+	if err != nil {
+		logger.Error("UpdateReq failed", zap.String("MsgID", req.MsgID), zap.Error(err))
+	}
+	return ok
+}
+
+// updateRespIf saves a response read by a scan of the store if its status is still 'status', see updateReqIf.
+func updateRespIf(resp *types.RespReceived, status string) bool {
+	ok, err := store.MsgObj.UpdateRespIf(resp, status)
+	if err != nil {
+		logger.Error("UpdateResp failed", zap.String("MsgID", resp.MsgID), zap.Error(err))
+	}
+	return ok
+}
+
+// ackRespondedReq marks a stored request as responded once its target responded to it: the response shows
+// the request was received even if its ack was lost, so it must not be sent again, and the request is
+// kept until its retention passes.
+func ackRespondedReq(msgID string, responder string) {
+	releaseRequest(responder, msgID)
+	req, err := store.MsgObj.GetReqByMsgID(msgID)
+	if err != nil {
+		logger.Error("GetReqByMsgID failed", zap.String("MsgID", msgID), zap.Error(err))
+		return
+	}
+	saveReqStatus(req, func(req *types.ReqReceived) bool {
+		if req.To != responder || req.Status == types.StatusResponded {
+			return false
 		}
+		req.Status = types.StatusResponded
+		return true
+	})
+}
+
+// ackMsgStatus records the ack of the client clientID for a message delivered to it. A negative ack
+// counts as a failed delivery, the message is sent again later.
+func ackMsgStatus(clientID string, ack *DMAckMsg) {
+	if clientID == "" {
+		//未完成Hi的会话不能确认消息
+		return
+	}
+	reason := ""
+	if !ack.IsOk {
+		reason = fmt.Sprintf("rejected by client: %s", ack.Msg)
+	}
+	if req, err := store.MsgObj.GetReqByMsgID(ack.MsgID); err == nil {
+		applyReqStatus(req, MsgSendStatus{MsgType: "req", MsgID: ack.MsgID, IsOk: ack.IsOk, Reason: reason, AckFrom: clientID})
+	} else if resp, err := store.MsgObj.GetRespByMsgID(ack.MsgID); err == nil {
+		applyRespStatus(resp, MsgSendStatus{MsgType: "resp", MsgID: ack.MsgID, IsOk: ack.IsOk, Reason: reason, AckFrom: clientID})
+	} else {
+		//非存储消息(如Presence、Pong)的确认无需处理
+		logger.Debug(fmt.Sprintf("Client Ack Msg: %v", ack))
 	}
 }
 
+// truncateError shortens a send failure reason to fit the last_error column.
+func truncateError(reason string) string {
+	const maxLen = 255
+	if len(reason) > maxLen {
+		return reason[:maxLen]
+	}
+	return reason
+}
+
 func grpcWrite(sess *Session, msg interface{}) error {
 	out := sess.grpcNode
 	if out != nil {
@@ -337,7 +1000,18 @@ func serveGrpc(addr string) (*grpc.Server, error) {
 		return nil, err
 	}
 
-	srv := grpc.NewServer(grpc.MaxRecvMsgSize(int(globals.configs.MaxMessageSize)))
+	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(int(globals.configs.MaxMessageSize))}
+	tlsConfig, err := serverTLSConfig(globals.configs.TLS)
+	if err != nil {
+		lis.Close()
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		logger.Info(fmt.Sprintf("gRPC server uses TLS %s+, client certificate required: %v", globals.configs.TLS.MinVersion, tlsConfig.ClientCAs != nil))
+	}
+
+	srv := grpc.NewServer(opts...)
 	golazy.RegisterNodeServer(srv, &grpcNodeServer{})
 	logger.Info(fmt.Sprintf("gRPC server is registered at [%s]", addr))
 
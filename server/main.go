@@ -7,21 +7,35 @@ import (
 	"os"
 
 	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/dato-live/golazy/server/auth"
 	"github.com/dato-live/golazy/server/config"
 	"github.com/dato-live/golazy/server/logs"
 	"github.com/dato-live/golazy/server/store"
 
 	//数据存储后端适配器
+	_ "github.com/dato-live/golazy/server/adapter/memory"
 	_ "github.com/dato-live/golazy/server/adapter/mysql"
 	_ "github.com/dato-live/golazy/server/adapter/sqlite"
 )
 
 var globals struct {
-	sessionStore *SessionStore
-	grpcServer   *grpc.Server
-	configs      config.Config
+	sessionStore   *SessionStore
+	callStore      *CallStore
+	broadcastStore *BroadcastStore
+	reqTracker     *ReqTracker
+	acl            *ACL
+	rateLimiter    *RateLimiter
+	grpcServer     *grpc.Server
+	configs        config.Config
+
+	// Closed to stop the background loops
+	stopLoops chan struct{}
+	// Running background loops
+	loops sync.WaitGroup
 }
 
 var logger *zap.Logger
@@ -56,26 +70,79 @@ func main() {
 		if err != nil {
 			logger.Fatal("Failed to connect to DB", zap.Error(err))
 		}
+		if err = auth.Open(configs.Auth); err != nil {
+			logger.Fatal("Failed to init client authentication", zap.Error(err))
+		}
 		defer func() {
 			store.Close()
 			logger.Info("Closed database connections")
 			logger.Info("All done, good bye")
 		}()
-		go store.DbClearLoop()
+		globals.stopLoops = make(chan struct{})
+		runLoop(store.DbClearLoop)
 
 		globals.sessionStore = NewSessionStore(time.Duration(configs.IdleSessionTimeoutSecond)*time.Second + 15*time.Second)
+		runLoop(globals.sessionStore.ExpireIdleSessionLoop)
+		globals.callStore = NewCallStore()
+		globals.broadcastStore = NewBroadcastStore()
+		globals.reqTracker = NewReqTracker()
+		globals.acl = NewACL(configs.ACL)
+		globals.rateLimiter = NewRateLimiter(configs.RateLimit)
+		runLoop(globals.reqTracker.ExpireRequestLoop)
 		globals.grpcServer, err = serveGrpc(configs.GrpcListen)
 		if err != nil {
 			logger.Fatal("Grpc server start error", zap.Error(err))
 		}
 
-		go RetrySendMsgLoop()
+		runLoop(RetrySendMsgLoop)
 
 		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt, os.Kill)
+		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 		<-c
-		logger.Info("Ctrl+C or Killed signal received,Graceful Exiting...")
-		globals.grpcServer.GracefulStop()
+		logger.Info("Ctrl+C or SIGTERM received,Graceful Exiting...")
+		shutdown(time.Duration(configs.ShutdownTimeoutSecond) * time.Second)
 		logger.Info("Graceful Exited.")
 	}
 }
+
+// runLoop starts a background loop which is stopped on shutdown.
+func runLoop(loop func(stop <-chan struct{})) {
+	globals.loops.Add(1)
+	go func() {
+		defer globals.loops.Done()
+		loop(globals.stopLoops)
+	}()
+}
+
+// shutdown stops the background loops, drains all sessions and stops the gRPC server.
+// Must be done before the store is closed.
+func shutdown(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+
+	//停止后台任务，避免关闭过程中继续重发消息
+	close(globals.stopLoops)
+	globals.loops.Wait()
+
+	if globals.grpcServer == nil {
+		globals.sessionStore.Shutdown(deadline)
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		//停止接收新连接，并等待已有会话结束
+		globals.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	globals.sessionStore.Shutdown(deadline)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Until(deadline)):
+		logger.Warn("Graceful stop timed out, closing remaining connections")
+		globals.grpcServer.Stop()
+		//连接关闭后发送队列中剩余的消息将被标记为失败
+		globals.sessionStore.writers.Wait()
+	}
+}
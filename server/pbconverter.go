@@ -15,9 +15,38 @@ func PBClientHiSerialize(msg *DMClientHi) *golazy.ClientMsg_Hi {
 			ClientDescription: msg.ClientDescription,
 			AllowedCommandIDs: msg.AllowedCommandIDs,
 			Timestamp:         timeToInt64(msg.Timestamp),
+			SubscribePresence: msg.SubscribePresence,
+			ServiceName:       msg.ServiceName,
+			Credentials:       PBCredentialsSerialize(msg.Credentials),
 		}}
 }
 
+func PBCredentialsSerialize(cred *DMCredentials) *golazy.Credentials {
+	if cred == nil {
+		return nil
+	}
+	return &golazy.Credentials{
+		Method:    cred.Method,
+		Token:     cred.Token,
+		Nonce:     cred.Nonce,
+		Timestamp: timeToInt64(cred.Timestamp),
+		Signature: cred.Signature,
+	}
+}
+
+func PBCredentialsDeserialize(cred *golazy.Credentials) *DMCredentials {
+	if cred == nil {
+		return nil
+	}
+	return &DMCredentials{
+		Method:    cred.GetMethod(),
+		Token:     cred.GetToken(),
+		Nonce:     cred.GetNonce(),
+		Timestamp: int64ToTime(cred.GetTimestamp()),
+		Signature: cred.GetSignature(),
+	}
+}
+
 func PBClientLeaveSerialize(msg *DMClientLeave) *golazy.ClientMsg_Leave {
 	return &golazy.ClientMsg_Leave{
 		Leave: &golazy.ClientLeave{
@@ -35,6 +64,10 @@ func PBClientReqSerialize(msg *DMClientReq) *golazy.ClientMsg_Req {
 			CommandID: msg.CommandID,
 			Content:   msg.Content,
 			Timestamp: timeToInt64(msg.Timestamp),
+			RouteKey:  msg.RouteKey,
+			Broadcast: msg.Broadcast,
+			Quorum:    msg.Quorum,
+			TimeoutMs: msg.TimeoutMs,
 		}}
 }
 
@@ -48,15 +81,101 @@ func PBClientRespSerialize(msg *DMClientResp) *golazy.ClientMsg_Resp {
 			ErrCode:   msg.ErrCode,
 			ErrMsg:    msg.ErrMsg,
 			Timestamp: timeToInt64(msg.Timestamp),
+			Results:   PBTargetRespSerialize(msg.Results),
 		}}
 }
 
+func PBTargetRespSerialize(results []*DMTargetResp) []*golazy.TargetResp {
+	if len(results) == 0 {
+		return nil
+	}
+	out := make([]*golazy.TargetResp, 0, len(results))
+	for _, r := range results {
+		out = append(out, &golazy.TargetResp{
+			ClientID: r.ClientID,
+			Content:  r.Content,
+			ErrCode:  r.ErrCode,
+			ErrMsg:   r.ErrMsg,
+		})
+	}
+	return out
+}
+
+func PBTargetRespDeserialize(results []*golazy.TargetResp) []*DMTargetResp {
+	if len(results) == 0 {
+		return nil
+	}
+	out := make([]*DMTargetResp, 0, len(results))
+	for _, r := range results {
+		out = append(out, &DMTargetResp{
+			ClientID: r.GetClientID(),
+			Content:  r.GetContent(),
+			ErrCode:  r.GetErrCode(),
+			ErrMsg:   r.GetErrMsg(),
+		})
+	}
+	return out
+}
+
 func PBAckMsgSerialize(msg *DMAckMsg) *golazy.ClientMsg_Ack {
 	return &golazy.ClientMsg_Ack{
 		Ack: &golazy.AckMsg{
-			MsgID:     msg.MsgID,
-			IsOk:      msg.IsOk,
-			Msg:       msg.Msg,
+			MsgID:        msg.MsgID,
+			IsOk:         msg.IsOk,
+			Msg:          msg.Msg,
+			Timestamp:    timeToInt64(msg.Timestamp),
+			ErrCode:      msg.ErrCode,
+			RetryAfterMs: msg.RetryAfterMs,
+		}}
+}
+
+func PBClientPresenceSerialize(msg *DMClientPresence) *golazy.ClientMsg_Presence {
+	return &golazy.ClientMsg_Presence{
+		Presence: &golazy.ClientPresence{
+			ClientID:      msg.ClientID,
+			ClientName:    msg.ClientName,
+			ClientVersion: msg.ClientVersion,
+			Event:         msg.Event,
+			Reason:        msg.Reason,
+			Timestamp:     timeToInt64(msg.Timestamp),
+		}}
+}
+
+func PBPingSerialize(msg *DMPing) *golazy.ClientMsg_Ping {
+	return &golazy.ClientMsg_Ping{
+		Ping: &golazy.Ping{
+			Timestamp: timeToInt64(msg.Timestamp),
+		}}
+}
+
+func PBPongSerialize(msg *DMPong) *golazy.ClientMsg_Pong {
+	return &golazy.ClientMsg_Pong{
+		Pong: &golazy.Pong{
+			PingTimestamp: timeToInt64(msg.PingTimestamp),
+			Timestamp:     timeToInt64(msg.Timestamp),
+		}}
+}
+
+func PBSubscribeSerialize(msg *DMSubscribe) *golazy.ClientMsg_Subscribe {
+	return &golazy.ClientMsg_Subscribe{
+		Subscribe: &golazy.Subscribe{
+			Topic: msg.Topic,
+		}}
+}
+
+func PBUnsubscribeSerialize(msg *DMUnsubscribe) *golazy.ClientMsg_Unsubscribe {
+	return &golazy.ClientMsg_Unsubscribe{
+		Unsubscribe: &golazy.Unsubscribe{
+			Topic: msg.Topic,
+		}}
+}
+
+func PBPublishSerialize(msg *DMPublish) *golazy.ClientMsg_Publish {
+	return &golazy.ClientMsg_Publish{
+		Publish: &golazy.Publish{
+			Topic:     msg.Topic,
+			From:      msg.From,
+			Content:   msg.Content,
 			Timestamp: timeToInt64(msg.Timestamp),
 		}}
 }
@@ -75,6 +194,18 @@ func PbSerialize(msg *DMClientMsg) *golazy.ClientMsg {
 		pkt.Message = PBClientRespSerialize(msg.Resp)
 	case msg.Ack != nil:
 		pkt.Message = PBAckMsgSerialize(msg.Ack)
+	case msg.Presence != nil:
+		pkt.Message = PBClientPresenceSerialize(msg.Presence)
+	case msg.Ping != nil:
+		pkt.Message = PBPingSerialize(msg.Ping)
+	case msg.Pong != nil:
+		pkt.Message = PBPongSerialize(msg.Pong)
+	case msg.Subscribe != nil:
+		pkt.Message = PBSubscribeSerialize(msg.Subscribe)
+	case msg.Unsubscribe != nil:
+		pkt.Message = PBUnsubscribeSerialize(msg.Unsubscribe)
+	case msg.Publish != nil:
+		pkt.Message = PBPublishSerialize(msg.Publish)
 	}
 	pkt.MsgID = msg.MsgID
 
@@ -91,6 +222,9 @@ func PbDeserialize(pkt *golazy.ClientMsg) *DMClientMsg {
 			ClientDescription: hi.GetClientDescription(),
 			AllowedCommandIDs: hi.GetAllowedCommandIDs(),
 			Timestamp:         int64ToTime(hi.GetTimestamp()),
+			SubscribePresence: hi.GetSubscribePresence(),
+			ServiceName:       hi.GetServiceName(),
+			Credentials:       PBCredentialsDeserialize(hi.GetCredentials()),
 		}
 	} else if leave := pkt.GetLeave(); leave != nil {
 		msg.Leave = &DMClientLeave{
@@ -105,6 +239,10 @@ func PbDeserialize(pkt *golazy.ClientMsg) *DMClientMsg {
 			CommandID: req.GetCommandID(),
 			Content:   req.GetContent(),
 			Timestamp: int64ToTime(req.GetTimestamp()),
+			RouteKey:  req.GetRouteKey(),
+			Broadcast: req.GetBroadcast(),
+			Quorum:    req.GetQuorum(),
+			TimeoutMs: req.GetTimeoutMs(),
 		}
 	} else if resp := pkt.GetResp(); resp != nil {
 		msg.Resp = &DMClientResp{
@@ -115,13 +253,49 @@ func PbDeserialize(pkt *golazy.ClientMsg) *DMClientMsg {
 			ErrCode:   resp.GetErrCode(),
 			ErrMsg:    resp.GetErrMsg(),
 			Timestamp: int64ToTime(resp.GetTimestamp()),
+			Results:   PBTargetRespDeserialize(resp.GetResults()),
 		}
 	} else if ack := pkt.GetAck(); ack != nil {
 		msg.Ack = &DMAckMsg{
-			MsgID:     ack.GetMsgID(),
-			IsOk:      ack.GetIsOk(),
-			Msg:       ack.GetMsg(),
-			Timestamp: int64ToTime(ack.GetTimestamp()),
+			MsgID:        ack.GetMsgID(),
+			IsOk:         ack.GetIsOk(),
+			Msg:          ack.GetMsg(),
+			Timestamp:    int64ToTime(ack.GetTimestamp()),
+			ErrCode:      ack.GetErrCode(),
+			RetryAfterMs: ack.GetRetryAfterMs(),
+		}
+	} else if presence := pkt.GetPresence(); presence != nil {
+		msg.Presence = &DMClientPresence{
+			ClientID:      presence.GetClientID(),
+			ClientName:    presence.GetClientName(),
+			ClientVersion: presence.GetClientVersion(),
+			Event:         presence.GetEvent(),
+			Reason:        presence.GetReason(),
+			Timestamp:     int64ToTime(presence.GetTimestamp()),
+		}
+	} else if ping := pkt.GetPing(); ping != nil {
+		msg.Ping = &DMPing{
+			Timestamp: int64ToTime(ping.GetTimestamp()),
+		}
+	} else if pong := pkt.GetPong(); pong != nil {
+		msg.Pong = &DMPong{
+			PingTimestamp: int64ToTime(pong.GetPingTimestamp()),
+			Timestamp:     int64ToTime(pong.GetTimestamp()),
+		}
+	} else if subscribe := pkt.GetSubscribe(); subscribe != nil {
+		msg.Subscribe = &DMSubscribe{
+			Topic: subscribe.GetTopic(),
+		}
+	} else if unsubscribe := pkt.GetUnsubscribe(); unsubscribe != nil {
+		msg.Unsubscribe = &DMUnsubscribe{
+			Topic: unsubscribe.GetTopic(),
+		}
+	} else if publish := pkt.GetPublish(); publish != nil {
+		msg.Publish = &DMPublish{
+			Topic:     publish.GetTopic(),
+			From:      publish.GetFrom(),
+			Content:   publish.GetContent(),
+			Timestamp: int64ToTime(publish.GetTimestamp()),
 		}
 	}
 
@@ -138,7 +312,7 @@ func timeToInt64(ts *time.Time) int64 {
 
 func int64ToTime(ts int64) *time.Time {
 	if ts > 0 {
-		res := time.Unix(ts/1000, ts%1000).UTC()
+		res := time.Unix(ts/1000, (ts%1000)*int64(time.Millisecond)).UTC()
 		return &res
 	}
 	return nil
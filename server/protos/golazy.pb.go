@@ -9,10 +9,26 @@ It is generated from these files:
 
 It has these top-level messages:
 	ClientHi
+	Credentials
 	ClientLeave
 	ClientReq
 	ClientResp
+	TargetResp
 	AckMsg
+	ClientPresence
+	Ping
+	Pong
+	Subscribe
+	Unsubscribe
+	Publish
+	ListClientsReq
+	ClientInfo
+	ListClientsResp
+	DeadLetterFilter
+	GetDeadLetterReq
+	DeadLetter
+	DeadLetterList
+	DeadLetterResult
 	ClientMsg
 */
 package golazy
@@ -44,6 +60,9 @@ type ClientHi struct {
 	ClientDescription string           `protobuf:"bytes,4,opt,name=ClientDescription" json:"ClientDescription,omitempty"`
 	AllowedCommandIDs map[int64]string `protobuf:"bytes,5,rep,name=AllowedCommandIDs" json:"AllowedCommandIDs,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	Timestamp         int64            `protobuf:"varint,6,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	SubscribePresence bool             `protobuf:"varint,7,opt,name=SubscribePresence" json:"SubscribePresence,omitempty"`
+	ServiceName       string           `protobuf:"bytes,8,opt,name=ServiceName" json:"ServiceName,omitempty"`
+	Credentials       *Credentials     `protobuf:"bytes,9,opt,name=Credentials" json:"Credentials,omitempty"`
 }
 
 func (m *ClientHi) Reset()                    { *m = ClientHi{} }
@@ -93,6 +112,75 @@ func (m *ClientHi) GetTimestamp() int64 {
 	return 0
 }
 
+func (m *ClientHi) GetSubscribePresence() bool {
+	if m != nil {
+		return m.SubscribePresence
+	}
+	return false
+}
+
+func (m *ClientHi) GetServiceName() string {
+	if m != nil {
+		return m.ServiceName
+	}
+	return ""
+}
+
+func (m *ClientHi) GetCredentials() *Credentials {
+	if m != nil {
+		return m.Credentials
+	}
+	return nil
+}
+
+type Credentials struct {
+	Method    string `protobuf:"bytes,1,opt,name=Method" json:"Method,omitempty"`
+	Token     string `protobuf:"bytes,2,opt,name=Token" json:"Token,omitempty"`
+	Nonce     string `protobuf:"bytes,3,opt,name=Nonce" json:"Nonce,omitempty"`
+	Timestamp int64  `protobuf:"varint,4,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	Signature string `protobuf:"bytes,5,opt,name=Signature" json:"Signature,omitempty"`
+}
+
+func (m *Credentials) Reset()                    { *m = Credentials{} }
+func (m *Credentials) String() string            { return proto.CompactTextString(m) }
+func (*Credentials) ProtoMessage()               {}
+func (*Credentials) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Credentials) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *Credentials) GetToken() string {
+	if m != nil {
+		return m.Token
+	}
+	return ""
+}
+
+func (m *Credentials) GetNonce() string {
+	if m != nil {
+		return m.Nonce
+	}
+	return ""
+}
+
+func (m *Credentials) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Credentials) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
 type ClientLeave struct {
 	ClientID  string `protobuf:"bytes,1,opt,name=ClientID" json:"ClientID,omitempty"`
 	Timestamp int64  `protobuf:"varint,2,opt,name=Timestamp" json:"Timestamp,omitempty"`
@@ -101,7 +189,7 @@ type ClientLeave struct {
 func (m *ClientLeave) Reset()                    { *m = ClientLeave{} }
 func (m *ClientLeave) String() string            { return proto.CompactTextString(m) }
 func (*ClientLeave) ProtoMessage()               {}
-func (*ClientLeave) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*ClientLeave) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
 func (m *ClientLeave) GetClientID() string {
 	if m != nil {
@@ -124,155 +212,731 @@ type ClientReq struct {
 	CommandID int64  `protobuf:"varint,4,opt,name=CommandID" json:"CommandID,omitempty"`
 	Content   string `protobuf:"bytes,5,opt,name=Content" json:"Content,omitempty"`
 	Timestamp int64  `protobuf:"varint,6,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	RouteKey  string `protobuf:"bytes,7,opt,name=RouteKey" json:"RouteKey,omitempty"`
+	Broadcast bool   `protobuf:"varint,8,opt,name=Broadcast" json:"Broadcast,omitempty"`
+	Quorum    int32  `protobuf:"varint,9,opt,name=Quorum" json:"Quorum,omitempty"`
+	TimeoutMs int64  `protobuf:"varint,10,opt,name=TimeoutMs" json:"TimeoutMs,omitempty"`
+}
+
+func (m *ClientReq) Reset()                    { *m = ClientReq{} }
+func (m *ClientReq) String() string            { return proto.CompactTextString(m) }
+func (*ClientReq) ProtoMessage()               {}
+func (*ClientReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *ClientReq) GetReqID() string {
+	if m != nil {
+		return m.ReqID
+	}
+	return ""
+}
+
+func (m *ClientReq) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *ClientReq) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *ClientReq) GetCommandID() int64 {
+	if m != nil {
+		return m.CommandID
+	}
+	return 0
+}
+
+func (m *ClientReq) GetContent() string {
+	if m != nil {
+		return m.Content
+	}
+	return ""
+}
+
+func (m *ClientReq) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *ClientReq) GetRouteKey() string {
+	if m != nil {
+		return m.RouteKey
+	}
+	return ""
+}
+
+func (m *ClientReq) GetBroadcast() bool {
+	if m != nil {
+		return m.Broadcast
+	}
+	return false
+}
+
+func (m *ClientReq) GetQuorum() int32 {
+	if m != nil {
+		return m.Quorum
+	}
+	return 0
+}
+
+func (m *ClientReq) GetTimeoutMs() int64 {
+	if m != nil {
+		return m.TimeoutMs
+	}
+	return 0
+}
+
+type ClientResp struct {
+	RespID    string        `protobuf:"bytes,1,opt,name=RespID" json:"RespID,omitempty"`
+	From      string        `protobuf:"bytes,2,opt,name=From" json:"From,omitempty"`
+	To        string        `protobuf:"bytes,3,opt,name=To" json:"To,omitempty"`
+	Content   string        `protobuf:"bytes,4,opt,name=Content" json:"Content,omitempty"`
+	ErrCode   int32         `protobuf:"varint,5,opt,name=ErrCode" json:"ErrCode,omitempty"`
+	ErrMsg    string        `protobuf:"bytes,6,opt,name=ErrMsg" json:"ErrMsg,omitempty"`
+	Timestamp int64         `protobuf:"varint,7,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	Results   []*TargetResp `protobuf:"bytes,8,rep,name=Results" json:"Results,omitempty"`
+}
+
+func (m *ClientResp) Reset()                    { *m = ClientResp{} }
+func (m *ClientResp) String() string            { return proto.CompactTextString(m) }
+func (*ClientResp) ProtoMessage()               {}
+func (*ClientResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *ClientResp) GetRespID() string {
+	if m != nil {
+		return m.RespID
+	}
+	return ""
+}
+
+func (m *ClientResp) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *ClientResp) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *ClientResp) GetContent() string {
+	if m != nil {
+		return m.Content
+	}
+	return ""
+}
+
+func (m *ClientResp) GetErrCode() int32 {
+	if m != nil {
+		return m.ErrCode
+	}
+	return 0
+}
+
+func (m *ClientResp) GetErrMsg() string {
+	if m != nil {
+		return m.ErrMsg
+	}
+	return ""
+}
+
+func (m *ClientResp) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *ClientResp) GetResults() []*TargetResp {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type TargetResp struct {
+	ClientID string `protobuf:"bytes,1,opt,name=ClientID" json:"ClientID,omitempty"`
+	Content  string `protobuf:"bytes,2,opt,name=Content" json:"Content,omitempty"`
+	ErrCode  int32  `protobuf:"varint,3,opt,name=ErrCode" json:"ErrCode,omitempty"`
+	ErrMsg   string `protobuf:"bytes,4,opt,name=ErrMsg" json:"ErrMsg,omitempty"`
+}
+
+func (m *TargetResp) Reset()                    { *m = TargetResp{} }
+func (m *TargetResp) String() string            { return proto.CompactTextString(m) }
+func (*TargetResp) ProtoMessage()               {}
+func (*TargetResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *TargetResp) GetClientID() string {
+	if m != nil {
+		return m.ClientID
+	}
+	return ""
+}
+
+func (m *TargetResp) GetContent() string {
+	if m != nil {
+		return m.Content
+	}
+	return ""
+}
+
+func (m *TargetResp) GetErrCode() int32 {
+	if m != nil {
+		return m.ErrCode
+	}
+	return 0
+}
+
+func (m *TargetResp) GetErrMsg() string {
+	if m != nil {
+		return m.ErrMsg
+	}
+	return ""
+}
+
+type AckMsg struct {
+	MsgID        string `protobuf:"bytes,1,opt,name=MsgID" json:"MsgID,omitempty"`
+	IsOk         bool   `protobuf:"varint,2,opt,name=IsOk" json:"IsOk,omitempty"`
+	Msg          string `protobuf:"bytes,3,opt,name=Msg" json:"Msg,omitempty"`
+	Timestamp    int64  `protobuf:"varint,4,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	ErrCode      int32  `protobuf:"varint,5,opt,name=ErrCode" json:"ErrCode,omitempty"`
+	RetryAfterMs int64  `protobuf:"varint,6,opt,name=RetryAfterMs" json:"RetryAfterMs,omitempty"`
+}
+
+func (m *AckMsg) Reset()                    { *m = AckMsg{} }
+func (m *AckMsg) String() string            { return proto.CompactTextString(m) }
+func (*AckMsg) ProtoMessage()               {}
+func (*AckMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *AckMsg) GetMsgID() string {
+	if m != nil {
+		return m.MsgID
+	}
+	return ""
+}
+
+func (m *AckMsg) GetIsOk() bool {
+	if m != nil {
+		return m.IsOk
+	}
+	return false
+}
+
+func (m *AckMsg) GetMsg() string {
+	if m != nil {
+		return m.Msg
+	}
+	return ""
+}
+
+func (m *AckMsg) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *AckMsg) GetErrCode() int32 {
+	if m != nil {
+		return m.ErrCode
+	}
+	return 0
+}
+
+func (m *AckMsg) GetRetryAfterMs() int64 {
+	if m != nil {
+		return m.RetryAfterMs
+	}
+	return 0
+}
+
+type ClientPresence struct {
+	ClientID      string `protobuf:"bytes,1,opt,name=ClientID" json:"ClientID,omitempty"`
+	ClientName    string `protobuf:"bytes,2,opt,name=ClientName" json:"ClientName,omitempty"`
+	ClientVersion string `protobuf:"bytes,3,opt,name=ClientVersion" json:"ClientVersion,omitempty"`
+	Event         string `protobuf:"bytes,4,opt,name=Event" json:"Event,omitempty"`
+	Reason        string `protobuf:"bytes,5,opt,name=Reason" json:"Reason,omitempty"`
+	Timestamp     int64  `protobuf:"varint,6,opt,name=Timestamp" json:"Timestamp,omitempty"`
+}
+
+func (m *ClientPresence) Reset()                    { *m = ClientPresence{} }
+func (m *ClientPresence) String() string            { return proto.CompactTextString(m) }
+func (*ClientPresence) ProtoMessage()               {}
+func (*ClientPresence) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *ClientPresence) GetClientID() string {
+	if m != nil {
+		return m.ClientID
+	}
+	return ""
+}
+
+func (m *ClientPresence) GetClientName() string {
+	if m != nil {
+		return m.ClientName
+	}
+	return ""
+}
+
+func (m *ClientPresence) GetClientVersion() string {
+	if m != nil {
+		return m.ClientVersion
+	}
+	return ""
+}
+
+func (m *ClientPresence) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *ClientPresence) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ClientPresence) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type Ping struct {
+	Timestamp int64 `protobuf:"varint,1,opt,name=Timestamp" json:"Timestamp,omitempty"`
+}
+
+func (m *Ping) Reset()                    { *m = Ping{} }
+func (m *Ping) String() string            { return proto.CompactTextString(m) }
+func (*Ping) ProtoMessage()               {}
+func (*Ping) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *Ping) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type Pong struct {
+	PingTimestamp int64 `protobuf:"varint,1,opt,name=PingTimestamp" json:"PingTimestamp,omitempty"`
+	Timestamp     int64 `protobuf:"varint,2,opt,name=Timestamp" json:"Timestamp,omitempty"`
+}
+
+func (m *Pong) Reset()                    { *m = Pong{} }
+func (m *Pong) String() string            { return proto.CompactTextString(m) }
+func (*Pong) ProtoMessage()               {}
+func (*Pong) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *Pong) GetPingTimestamp() int64 {
+	if m != nil {
+		return m.PingTimestamp
+	}
+	return 0
+}
+
+func (m *Pong) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type Subscribe struct {
+	Topic string `protobuf:"bytes,1,opt,name=Topic" json:"Topic,omitempty"`
+}
+
+func (m *Subscribe) Reset()                    { *m = Subscribe{} }
+func (m *Subscribe) String() string            { return proto.CompactTextString(m) }
+func (*Subscribe) ProtoMessage()               {}
+func (*Subscribe) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *Subscribe) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+type Unsubscribe struct {
+	Topic string `protobuf:"bytes,1,opt,name=Topic" json:"Topic,omitempty"`
+}
+
+func (m *Unsubscribe) Reset()                    { *m = Unsubscribe{} }
+func (m *Unsubscribe) String() string            { return proto.CompactTextString(m) }
+func (*Unsubscribe) ProtoMessage()               {}
+func (*Unsubscribe) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *Unsubscribe) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+type Publish struct {
+	Topic     string `protobuf:"bytes,1,opt,name=Topic" json:"Topic,omitempty"`
+	From      string `protobuf:"bytes,2,opt,name=From" json:"From,omitempty"`
+	Content   string `protobuf:"bytes,3,opt,name=Content" json:"Content,omitempty"`
+	Timestamp int64  `protobuf:"varint,4,opt,name=Timestamp" json:"Timestamp,omitempty"`
+}
+
+func (m *Publish) Reset()                    { *m = Publish{} }
+func (m *Publish) String() string            { return proto.CompactTextString(m) }
+func (*Publish) ProtoMessage()               {}
+func (*Publish) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *Publish) GetTopic() string {
+	if m != nil {
+		return m.Topic
+	}
+	return ""
+}
+
+func (m *Publish) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *Publish) GetContent() string {
+	if m != nil {
+		return m.Content
+	}
+	return ""
+}
+
+func (m *Publish) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type ListClientsReq struct {
+	NamePrefix string  `protobuf:"bytes,1,opt,name=NamePrefix" json:"NamePrefix,omitempty"`
+	CommandIDs []int64 `protobuf:"varint,2,rep,packed,name=CommandIDs" json:"CommandIDs,omitempty"`
+}
+
+func (m *ListClientsReq) Reset()                    { *m = ListClientsReq{} }
+func (m *ListClientsReq) String() string            { return proto.CompactTextString(m) }
+func (*ListClientsReq) ProtoMessage()               {}
+func (*ListClientsReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *ListClientsReq) GetNamePrefix() string {
+	if m != nil {
+		return m.NamePrefix
+	}
+	return ""
+}
+
+func (m *ListClientsReq) GetCommandIDs() []int64 {
+	if m != nil {
+		return m.CommandIDs
+	}
+	return nil
+}
+
+type ClientInfo struct {
+	ClientID          string           `protobuf:"bytes,1,opt,name=ClientID" json:"ClientID,omitempty"`
+	ClientName        string           `protobuf:"bytes,2,opt,name=ClientName" json:"ClientName,omitempty"`
+	ClientVersion     string           `protobuf:"bytes,3,opt,name=ClientVersion" json:"ClientVersion,omitempty"`
+	ClientDescription string           `protobuf:"bytes,4,opt,name=ClientDescription" json:"ClientDescription,omitempty"`
+	AllowedCommandIDs map[int64]string `protobuf:"bytes,5,rep,name=AllowedCommandIDs" json:"AllowedCommandIDs,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RemoteAddr        string           `protobuf:"bytes,6,opt,name=RemoteAddr" json:"RemoteAddr,omitempty"`
+	ConnectedAt       int64            `protobuf:"varint,7,opt,name=ConnectedAt" json:"ConnectedAt,omitempty"`
+	ServiceName       string           `protobuf:"bytes,8,opt,name=ServiceName" json:"ServiceName,omitempty"`
+}
+
+func (m *ClientInfo) Reset()                    { *m = ClientInfo{} }
+func (m *ClientInfo) String() string            { return proto.CompactTextString(m) }
+func (*ClientInfo) ProtoMessage()               {}
+func (*ClientInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *ClientInfo) GetClientID() string {
+	if m != nil {
+		return m.ClientID
+	}
+	return ""
+}
+
+func (m *ClientInfo) GetClientName() string {
+	if m != nil {
+		return m.ClientName
+	}
+	return ""
+}
+
+func (m *ClientInfo) GetClientVersion() string {
+	if m != nil {
+		return m.ClientVersion
+	}
+	return ""
+}
+
+func (m *ClientInfo) GetClientDescription() string {
+	if m != nil {
+		return m.ClientDescription
+	}
+	return ""
+}
+
+func (m *ClientInfo) GetAllowedCommandIDs() map[int64]string {
+	if m != nil {
+		return m.AllowedCommandIDs
+	}
+	return nil
+}
+
+func (m *ClientInfo) GetRemoteAddr() string {
+	if m != nil {
+		return m.RemoteAddr
+	}
+	return ""
+}
+
+func (m *ClientInfo) GetConnectedAt() int64 {
+	if m != nil {
+		return m.ConnectedAt
+	}
+	return 0
+}
+
+func (m *ClientInfo) GetServiceName() string {
+	if m != nil {
+		return m.ServiceName
+	}
+	return ""
+}
+
+type ListClientsResp struct {
+	Clients []*ClientInfo `protobuf:"bytes,1,rep,name=Clients" json:"Clients,omitempty"`
+}
+
+func (m *ListClientsResp) Reset()                    { *m = ListClientsResp{} }
+func (m *ListClientsResp) String() string            { return proto.CompactTextString(m) }
+func (*ListClientsResp) ProtoMessage()               {}
+func (*ListClientsResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *ListClientsResp) GetClients() []*ClientInfo {
+	if m != nil {
+		return m.Clients
+	}
+	return nil
+}
+
+type DeadLetterFilter struct {
+	MsgID    string `protobuf:"bytes,1,opt,name=MsgID" json:"MsgID,omitempty"`
+	ClientID string `protobuf:"bytes,2,opt,name=ClientID" json:"ClientID,omitempty"`
+	ReqID    string `protobuf:"bytes,3,opt,name=ReqID" json:"ReqID,omitempty"`
+	Since    int64  `protobuf:"varint,4,opt,name=Since" json:"Since,omitempty"`
+	Until    int64  `protobuf:"varint,5,opt,name=Until" json:"Until,omitempty"`
+	Limit    int32  `protobuf:"varint,6,opt,name=Limit" json:"Limit,omitempty"`
+	All      bool   `protobuf:"varint,7,opt,name=All" json:"All,omitempty"`
 }
 
-func (m *ClientReq) Reset()                    { *m = ClientReq{} }
-func (m *ClientReq) String() string            { return proto.CompactTextString(m) }
-func (*ClientReq) ProtoMessage()               {}
-func (*ClientReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (m *DeadLetterFilter) Reset()                    { *m = DeadLetterFilter{} }
+func (m *DeadLetterFilter) String() string            { return proto.CompactTextString(m) }
+func (*DeadLetterFilter) ProtoMessage()               {}
+func (*DeadLetterFilter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
-func (m *ClientReq) GetReqID() string {
+func (m *DeadLetterFilter) GetMsgID() string {
 	if m != nil {
-		return m.ReqID
+		return m.MsgID
 	}
 	return ""
 }
 
-func (m *ClientReq) GetFrom() string {
+func (m *DeadLetterFilter) GetClientID() string {
 	if m != nil {
-		return m.From
+		return m.ClientID
 	}
 	return ""
 }
 
-func (m *ClientReq) GetTo() string {
+func (m *DeadLetterFilter) GetReqID() string {
 	if m != nil {
-		return m.To
+		return m.ReqID
 	}
 	return ""
 }
 
-func (m *ClientReq) GetCommandID() int64 {
+func (m *DeadLetterFilter) GetSince() int64 {
 	if m != nil {
-		return m.CommandID
+		return m.Since
 	}
 	return 0
 }
 
-func (m *ClientReq) GetContent() string {
+func (m *DeadLetterFilter) GetUntil() int64 {
 	if m != nil {
-		return m.Content
+		return m.Until
 	}
-	return ""
+	return 0
 }
 
-func (m *ClientReq) GetTimestamp() int64 {
+func (m *DeadLetterFilter) GetLimit() int32 {
 	if m != nil {
-		return m.Timestamp
+		return m.Limit
 	}
 	return 0
 }
 
-type ClientResp struct {
-	RespID    string `protobuf:"bytes,1,opt,name=RespID" json:"RespID,omitempty"`
-	From      string `protobuf:"bytes,2,opt,name=From" json:"From,omitempty"`
-	To        string `protobuf:"bytes,3,opt,name=To" json:"To,omitempty"`
-	Content   string `protobuf:"bytes,4,opt,name=Content" json:"Content,omitempty"`
-	ErrCode   int32  `protobuf:"varint,5,opt,name=ErrCode" json:"ErrCode,omitempty"`
-	ErrMsg    string `protobuf:"bytes,6,opt,name=ErrMsg" json:"ErrMsg,omitempty"`
-	Timestamp int64  `protobuf:"varint,7,opt,name=Timestamp" json:"Timestamp,omitempty"`
+func (m *DeadLetterFilter) GetAll() bool {
+	if m != nil {
+		return m.All
+	}
+	return false
 }
 
-func (m *ClientResp) Reset()                    { *m = ClientResp{} }
-func (m *ClientResp) String() string            { return proto.CompactTextString(m) }
-func (*ClientResp) ProtoMessage()               {}
-func (*ClientResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+type GetDeadLetterReq struct {
+	MsgID string `protobuf:"bytes,1,opt,name=MsgID" json:"MsgID,omitempty"`
+}
 
-func (m *ClientResp) GetRespID() string {
+func (m *GetDeadLetterReq) Reset()                    { *m = GetDeadLetterReq{} }
+func (m *GetDeadLetterReq) String() string            { return proto.CompactTextString(m) }
+func (*GetDeadLetterReq) ProtoMessage()               {}
+func (*GetDeadLetterReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *GetDeadLetterReq) GetMsgID() string {
 	if m != nil {
-		return m.RespID
+		return m.MsgID
 	}
 	return ""
 }
 
-func (m *ClientResp) GetFrom() string {
+type DeadLetter struct {
+	MsgID     string `protobuf:"bytes,1,opt,name=MsgID" json:"MsgID,omitempty"`
+	MsgType   string `protobuf:"bytes,2,opt,name=MsgType" json:"MsgType,omitempty"`
+	ReqID     string `protobuf:"bytes,3,opt,name=ReqID" json:"ReqID,omitempty"`
+	From      string `protobuf:"bytes,4,opt,name=From" json:"From,omitempty"`
+	To        string `protobuf:"bytes,5,opt,name=To" json:"To,omitempty"`
+	Retries   int32  `protobuf:"varint,6,opt,name=Retries" json:"Retries,omitempty"`
+	LastError string `protobuf:"bytes,7,opt,name=LastError" json:"LastError,omitempty"`
+	Added     int64  `protobuf:"varint,8,opt,name=Added" json:"Added,omitempty"`
+	ExpiresAt int64  `protobuf:"varint,9,opt,name=ExpiresAt" json:"ExpiresAt,omitempty"`
+	Content   string `protobuf:"bytes,10,opt,name=Content" json:"Content,omitempty"`
+}
+
+func (m *DeadLetter) Reset()                    { *m = DeadLetter{} }
+func (m *DeadLetter) String() string            { return proto.CompactTextString(m) }
+func (*DeadLetter) ProtoMessage()               {}
+func (*DeadLetter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *DeadLetter) GetMsgID() string {
 	if m != nil {
-		return m.From
+		return m.MsgID
 	}
 	return ""
 }
 
-func (m *ClientResp) GetTo() string {
+func (m *DeadLetter) GetMsgType() string {
 	if m != nil {
-		return m.To
+		return m.MsgType
 	}
 	return ""
 }
 
-func (m *ClientResp) GetContent() string {
+func (m *DeadLetter) GetReqID() string {
 	if m != nil {
-		return m.Content
+		return m.ReqID
 	}
 	return ""
 }
 
-func (m *ClientResp) GetErrCode() int32 {
+func (m *DeadLetter) GetFrom() string {
 	if m != nil {
-		return m.ErrCode
+		return m.From
 	}
-	return 0
+	return ""
 }
 
-func (m *ClientResp) GetErrMsg() string {
+func (m *DeadLetter) GetTo() string {
 	if m != nil {
-		return m.ErrMsg
+		return m.To
 	}
 	return ""
 }
 
-func (m *ClientResp) GetTimestamp() int64 {
+func (m *DeadLetter) GetRetries() int32 {
 	if m != nil {
-		return m.Timestamp
+		return m.Retries
 	}
 	return 0
 }
 
-type AckMsg struct {
-	MsgID     string `protobuf:"bytes,1,opt,name=MsgID" json:"MsgID,omitempty"`
-	IsOk      bool   `protobuf:"varint,2,opt,name=IsOk" json:"IsOk,omitempty"`
-	Msg       string `protobuf:"bytes,3,opt,name=Msg" json:"Msg,omitempty"`
-	Timestamp int64  `protobuf:"varint,4,opt,name=Timestamp" json:"Timestamp,omitempty"`
+func (m *DeadLetter) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
 }
 
-func (m *AckMsg) Reset()                    { *m = AckMsg{} }
-func (m *AckMsg) String() string            { return proto.CompactTextString(m) }
-func (*AckMsg) ProtoMessage()               {}
-func (*AckMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
-
-func (m *AckMsg) GetMsgID() string {
+func (m *DeadLetter) GetAdded() int64 {
 	if m != nil {
-		return m.MsgID
+		return m.Added
 	}
-	return ""
+	return 0
 }
 
-func (m *AckMsg) GetIsOk() bool {
+func (m *DeadLetter) GetExpiresAt() int64 {
 	if m != nil {
-		return m.IsOk
+		return m.ExpiresAt
 	}
-	return false
+	return 0
 }
 
-func (m *AckMsg) GetMsg() string {
+func (m *DeadLetter) GetContent() string {
 	if m != nil {
-		return m.Msg
+		return m.Content
 	}
 	return ""
 }
 
-func (m *AckMsg) GetTimestamp() int64 {
+type DeadLetterList struct {
+	DeadLetters []*DeadLetter `protobuf:"bytes,1,rep,name=DeadLetters" json:"DeadLetters,omitempty"`
+}
+
+func (m *DeadLetterList) Reset()                    { *m = DeadLetterList{} }
+func (m *DeadLetterList) String() string            { return proto.CompactTextString(m) }
+func (*DeadLetterList) ProtoMessage()               {}
+func (*DeadLetterList) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *DeadLetterList) GetDeadLetters() []*DeadLetter {
 	if m != nil {
-		return m.Timestamp
+		return m.DeadLetters
+	}
+	return nil
+}
+
+type DeadLetterResult struct {
+	Count int64 `protobuf:"varint,1,opt,name=Count" json:"Count,omitempty"`
+}
+
+func (m *DeadLetterResult) Reset()                    { *m = DeadLetterResult{} }
+func (m *DeadLetterResult) String() string            { return proto.CompactTextString(m) }
+func (*DeadLetterResult) ProtoMessage()               {}
+func (*DeadLetterResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *DeadLetterResult) GetCount() int64 {
+	if m != nil {
+		return m.Count
 	}
 	return 0
 }
@@ -284,6 +948,12 @@ type ClientMsg struct {
 	//	*ClientMsg_Req
 	//	*ClientMsg_Resp
 	//	*ClientMsg_Ack
+	//	*ClientMsg_Presence
+	//	*ClientMsg_Ping
+	//	*ClientMsg_Pong
+	//	*ClientMsg_Subscribe
+	//	*ClientMsg_Unsubscribe
+	//	*ClientMsg_Publish
 	Message isClientMsg_Message `protobuf_oneof:"Message"`
 	MsgID   string              `protobuf:"bytes,6,opt,name=MsgID" json:"MsgID,omitempty"`
 }
@@ -291,7 +961,7 @@ type ClientMsg struct {
 func (m *ClientMsg) Reset()                    { *m = ClientMsg{} }
 func (m *ClientMsg) String() string            { return proto.CompactTextString(m) }
 func (*ClientMsg) ProtoMessage()               {}
-func (*ClientMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*ClientMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 type isClientMsg_Message interface {
 	isClientMsg_Message()
@@ -312,12 +982,36 @@ type ClientMsg_Resp struct {
 type ClientMsg_Ack struct {
 	Ack *AckMsg `protobuf:"bytes,5,opt,name=Ack,oneof"`
 }
+type ClientMsg_Presence struct {
+	Presence *ClientPresence `protobuf:"bytes,7,opt,name=Presence,oneof"`
+}
+type ClientMsg_Ping struct {
+	Ping *Ping `protobuf:"bytes,8,opt,name=Ping,oneof"`
+}
+type ClientMsg_Pong struct {
+	Pong *Pong `protobuf:"bytes,9,opt,name=Pong,oneof"`
+}
+type ClientMsg_Subscribe struct {
+	Subscribe *Subscribe `protobuf:"bytes,10,opt,name=Subscribe,oneof"`
+}
+type ClientMsg_Unsubscribe struct {
+	Unsubscribe *Unsubscribe `protobuf:"bytes,11,opt,name=Unsubscribe,oneof"`
+}
+type ClientMsg_Publish struct {
+	Publish *Publish `protobuf:"bytes,12,opt,name=Publish,oneof"`
+}
 
-func (*ClientMsg_Hi) isClientMsg_Message()    {}
-func (*ClientMsg_Leave) isClientMsg_Message() {}
-func (*ClientMsg_Req) isClientMsg_Message()   {}
-func (*ClientMsg_Resp) isClientMsg_Message()  {}
-func (*ClientMsg_Ack) isClientMsg_Message()   {}
+func (*ClientMsg_Hi) isClientMsg_Message()          {}
+func (*ClientMsg_Leave) isClientMsg_Message()       {}
+func (*ClientMsg_Req) isClientMsg_Message()         {}
+func (*ClientMsg_Resp) isClientMsg_Message()        {}
+func (*ClientMsg_Ack) isClientMsg_Message()         {}
+func (*ClientMsg_Presence) isClientMsg_Message()    {}
+func (*ClientMsg_Ping) isClientMsg_Message()        {}
+func (*ClientMsg_Pong) isClientMsg_Message()        {}
+func (*ClientMsg_Subscribe) isClientMsg_Message()   {}
+func (*ClientMsg_Unsubscribe) isClientMsg_Message() {}
+func (*ClientMsg_Publish) isClientMsg_Message()     {}
 
 func (m *ClientMsg) GetMessage() isClientMsg_Message {
 	if m != nil {
@@ -361,6 +1055,48 @@ func (m *ClientMsg) GetAck() *AckMsg {
 	return nil
 }
 
+func (m *ClientMsg) GetPresence() *ClientPresence {
+	if x, ok := m.GetMessage().(*ClientMsg_Presence); ok {
+		return x.Presence
+	}
+	return nil
+}
+
+func (m *ClientMsg) GetPing() *Ping {
+	if x, ok := m.GetMessage().(*ClientMsg_Ping); ok {
+		return x.Ping
+	}
+	return nil
+}
+
+func (m *ClientMsg) GetPong() *Pong {
+	if x, ok := m.GetMessage().(*ClientMsg_Pong); ok {
+		return x.Pong
+	}
+	return nil
+}
+
+func (m *ClientMsg) GetSubscribe() *Subscribe {
+	if x, ok := m.GetMessage().(*ClientMsg_Subscribe); ok {
+		return x.Subscribe
+	}
+	return nil
+}
+
+func (m *ClientMsg) GetUnsubscribe() *Unsubscribe {
+	if x, ok := m.GetMessage().(*ClientMsg_Unsubscribe); ok {
+		return x.Unsubscribe
+	}
+	return nil
+}
+
+func (m *ClientMsg) GetPublish() *Publish {
+	if x, ok := m.GetMessage().(*ClientMsg_Publish); ok {
+		return x.Publish
+	}
+	return nil
+}
+
 func (m *ClientMsg) GetMsgID() string {
 	if m != nil {
 		return m.MsgID
@@ -376,6 +1112,12 @@ func (*ClientMsg) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) err
 		(*ClientMsg_Req)(nil),
 		(*ClientMsg_Resp)(nil),
 		(*ClientMsg_Ack)(nil),
+		(*ClientMsg_Presence)(nil),
+		(*ClientMsg_Ping)(nil),
+		(*ClientMsg_Pong)(nil),
+		(*ClientMsg_Subscribe)(nil),
+		(*ClientMsg_Unsubscribe)(nil),
+		(*ClientMsg_Publish)(nil),
 	}
 }
 
@@ -408,6 +1150,36 @@ func _ClientMsg_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.Ack); err != nil {
 			return err
 		}
+	case *ClientMsg_Presence:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Presence); err != nil {
+			return err
+		}
+	case *ClientMsg_Ping:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Ping); err != nil {
+			return err
+		}
+	case *ClientMsg_Pong:
+		b.EncodeVarint(9<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Pong); err != nil {
+			return err
+		}
+	case *ClientMsg_Subscribe:
+		b.EncodeVarint(10<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Subscribe); err != nil {
+			return err
+		}
+	case *ClientMsg_Unsubscribe:
+		b.EncodeVarint(11<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Unsubscribe); err != nil {
+			return err
+		}
+	case *ClientMsg_Publish:
+		b.EncodeVarint(12<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Publish); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("ClientMsg.Message has unexpected type %T", x)
@@ -458,6 +1230,54 @@ func _ClientMsg_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buff
 		err := b.DecodeMessage(msg)
 		m.Message = &ClientMsg_Ack{msg}
 		return true, err
+	case 7: // Message.Presence
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ClientPresence)
+		err := b.DecodeMessage(msg)
+		m.Message = &ClientMsg_Presence{msg}
+		return true, err
+	case 8: // Message.Ping
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Ping)
+		err := b.DecodeMessage(msg)
+		m.Message = &ClientMsg_Ping{msg}
+		return true, err
+	case 9: // Message.Pong
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Pong)
+		err := b.DecodeMessage(msg)
+		m.Message = &ClientMsg_Pong{msg}
+		return true, err
+	case 10: // Message.Subscribe
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Subscribe)
+		err := b.DecodeMessage(msg)
+		m.Message = &ClientMsg_Subscribe{msg}
+		return true, err
+	case 11: // Message.Unsubscribe
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Unsubscribe)
+		err := b.DecodeMessage(msg)
+		m.Message = &ClientMsg_Unsubscribe{msg}
+		return true, err
+	case 12: // Message.Publish
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Publish)
+		err := b.DecodeMessage(msg)
+		m.Message = &ClientMsg_Publish{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -492,6 +1312,36 @@ func _ClientMsg_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(5<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *ClientMsg_Presence:
+		s := proto.Size(x.Presence)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ClientMsg_Ping:
+		s := proto.Size(x.Ping)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ClientMsg_Pong:
+		s := proto.Size(x.Pong)
+		n += proto.SizeVarint(9<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ClientMsg_Subscribe:
+		s := proto.Size(x.Subscribe)
+		n += proto.SizeVarint(10<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ClientMsg_Unsubscribe:
+		s := proto.Size(x.Unsubscribe)
+		n += proto.SizeVarint(11<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *ClientMsg_Publish:
+		s := proto.Size(x.Publish)
+		n += proto.SizeVarint(12<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -501,10 +1351,26 @@ func _ClientMsg_OneofSizer(msg proto.Message) (n int) {
 
 func init() {
 	proto.RegisterType((*ClientHi)(nil), "golazy.ClientHi")
+	proto.RegisterType((*Credentials)(nil), "golazy.Credentials")
 	proto.RegisterType((*ClientLeave)(nil), "golazy.ClientLeave")
 	proto.RegisterType((*ClientReq)(nil), "golazy.ClientReq")
 	proto.RegisterType((*ClientResp)(nil), "golazy.ClientResp")
+	proto.RegisterType((*TargetResp)(nil), "golazy.TargetResp")
 	proto.RegisterType((*AckMsg)(nil), "golazy.AckMsg")
+	proto.RegisterType((*ClientPresence)(nil), "golazy.ClientPresence")
+	proto.RegisterType((*Ping)(nil), "golazy.Ping")
+	proto.RegisterType((*Pong)(nil), "golazy.Pong")
+	proto.RegisterType((*Subscribe)(nil), "golazy.Subscribe")
+	proto.RegisterType((*Unsubscribe)(nil), "golazy.Unsubscribe")
+	proto.RegisterType((*Publish)(nil), "golazy.Publish")
+	proto.RegisterType((*ListClientsReq)(nil), "golazy.ListClientsReq")
+	proto.RegisterType((*ClientInfo)(nil), "golazy.ClientInfo")
+	proto.RegisterType((*ListClientsResp)(nil), "golazy.ListClientsResp")
+	proto.RegisterType((*DeadLetterFilter)(nil), "golazy.DeadLetterFilter")
+	proto.RegisterType((*GetDeadLetterReq)(nil), "golazy.GetDeadLetterReq")
+	proto.RegisterType((*DeadLetter)(nil), "golazy.DeadLetter")
+	proto.RegisterType((*DeadLetterList)(nil), "golazy.DeadLetterList")
+	proto.RegisterType((*DeadLetterResult)(nil), "golazy.DeadLetterResult")
 	proto.RegisterType((*ClientMsg)(nil), "golazy.ClientMsg")
 }
 
@@ -520,6 +1386,12 @@ const _ = grpc.SupportPackageIsVersion4
 
 type NodeClient interface {
 	MessageLoop(ctx context.Context, opts ...grpc.CallOption) (Node_MessageLoopClient, error)
+	Call(ctx context.Context, in *ClientReq, opts ...grpc.CallOption) (*ClientResp, error)
+	ListClients(ctx context.Context, in *ListClientsReq, opts ...grpc.CallOption) (*ListClientsResp, error)
+	ListDeadLetters(ctx context.Context, in *DeadLetterFilter, opts ...grpc.CallOption) (*DeadLetterList, error)
+	GetDeadLetter(ctx context.Context, in *GetDeadLetterReq, opts ...grpc.CallOption) (*DeadLetter, error)
+	RequeueDeadLetters(ctx context.Context, in *DeadLetterFilter, opts ...grpc.CallOption) (*DeadLetterResult, error)
+	PurgeDeadLetters(ctx context.Context, in *DeadLetterFilter, opts ...grpc.CallOption) (*DeadLetterResult, error)
 }
 
 type nodeClient struct {
@@ -561,10 +1433,70 @@ func (x *nodeMessageLoopClient) Recv() (*ClientMsg, error) {
 	return m, nil
 }
 
+func (c *nodeClient) Call(ctx context.Context, in *ClientReq, opts ...grpc.CallOption) (*ClientResp, error) {
+	out := new(ClientResp)
+	err := grpc.Invoke(ctx, "/golazy.Node/Call", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ListClients(ctx context.Context, in *ListClientsReq, opts ...grpc.CallOption) (*ListClientsResp, error) {
+	out := new(ListClientsResp)
+	err := grpc.Invoke(ctx, "/golazy.Node/ListClients", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) ListDeadLetters(ctx context.Context, in *DeadLetterFilter, opts ...grpc.CallOption) (*DeadLetterList, error) {
+	out := new(DeadLetterList)
+	err := grpc.Invoke(ctx, "/golazy.Node/ListDeadLetters", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) GetDeadLetter(ctx context.Context, in *GetDeadLetterReq, opts ...grpc.CallOption) (*DeadLetter, error) {
+	out := new(DeadLetter)
+	err := grpc.Invoke(ctx, "/golazy.Node/GetDeadLetter", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) RequeueDeadLetters(ctx context.Context, in *DeadLetterFilter, opts ...grpc.CallOption) (*DeadLetterResult, error) {
+	out := new(DeadLetterResult)
+	err := grpc.Invoke(ctx, "/golazy.Node/RequeueDeadLetters", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeClient) PurgeDeadLetters(ctx context.Context, in *DeadLetterFilter, opts ...grpc.CallOption) (*DeadLetterResult, error) {
+	out := new(DeadLetterResult)
+	err := grpc.Invoke(ctx, "/golazy.Node/PurgeDeadLetters", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Node service
 
 type NodeServer interface {
 	MessageLoop(Node_MessageLoopServer) error
+	Call(context.Context, *ClientReq) (*ClientResp, error)
+	ListClients(context.Context, *ListClientsReq) (*ListClientsResp, error)
+	ListDeadLetters(context.Context, *DeadLetterFilter) (*DeadLetterList, error)
+	GetDeadLetter(context.Context, *GetDeadLetterReq) (*DeadLetter, error)
+	RequeueDeadLetters(context.Context, *DeadLetterFilter) (*DeadLetterResult, error)
+	PurgeDeadLetters(context.Context, *DeadLetterFilter) (*DeadLetterResult, error)
 }
 
 func RegisterNodeServer(s *grpc.Server, srv NodeServer) {
@@ -597,10 +1529,143 @@ func (x *nodeMessageLoopServer) Recv() (*ClientMsg, error) {
 	return m, nil
 }
 
+func _Node_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClientReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/golazy.Node/Call",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).Call(ctx, req.(*ClientReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_ListClients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListClientsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).ListClients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/golazy.Node/ListClients",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).ListClients(ctx, req.(*ListClientsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_ListDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).ListDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/golazy.Node/ListDeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).ListDeadLetters(ctx, req.(*DeadLetterFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_GetDeadLetter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeadLetterReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).GetDeadLetter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/golazy.Node/GetDeadLetter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).GetDeadLetter(ctx, req.(*GetDeadLetterReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_RequeueDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).RequeueDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/golazy.Node/RequeueDeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).RequeueDeadLetters(ctx, req.(*DeadLetterFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Node_PurgeDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLetterFilter)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServer).PurgeDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/golazy.Node/PurgeDeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServer).PurgeDeadLetters(ctx, req.(*DeadLetterFilter))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Node_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "golazy.Node",
 	HandlerType: (*NodeServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Call",
+			Handler:    _Node_Call_Handler,
+		},
+		{
+			MethodName: "ListClients",
+			Handler:    _Node_ListClients_Handler,
+		},
+		{
+			MethodName: "ListDeadLetters",
+			Handler:    _Node_ListDeadLetters_Handler,
+		},
+		{
+			MethodName: "GetDeadLetter",
+			Handler:    _Node_GetDeadLetter_Handler,
+		},
+		{
+			MethodName: "RequeueDeadLetters",
+			Handler:    _Node_RequeueDeadLetters_Handler,
+		},
+		{
+			MethodName: "PurgeDeadLetters",
+			Handler:    _Node_PurgeDeadLetters_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "MessageLoop",
@@ -615,39 +1680,91 @@ var _Node_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("golazy.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 543 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x94, 0xdf, 0x8a, 0xd3, 0x40,
-	0x14, 0xc6, 0x9b, 0xbf, 0xdd, 0x9e, 0xe8, 0xb2, 0x1d, 0x97, 0x25, 0x14, 0x91, 0x12, 0x14, 0x0b,
-	0x4a, 0x91, 0x78, 0xa3, 0xde, 0xd5, 0xb6, 0x9a, 0xc2, 0x76, 0x85, 0xa1, 0x7a, 0x9f, 0x6d, 0x87,
-	0x12, 0xda, 0x64, 0xd2, 0x4c, 0x5c, 0xa9, 0xaf, 0xe2, 0x5b, 0xf8, 0x2e, 0xbe, 0x88, 0x4f, 0x20,
-	0x73, 0x26, 0x69, 0x9b, 0x44, 0x96, 0xbd, 0xda, 0xf3, 0x9d, 0xf9, 0x76, 0xce, 0x2f, 0xdf, 0x19,
-	0x0a, 0x8f, 0xd6, 0x7c, 0x1b, 0xfe, 0xdc, 0x0f, 0xd3, 0x8c, 0xe7, 0x9c, 0xd8, 0x4a, 0x79, 0x7f,
-	0x74, 0x38, 0x1b, 0x6f, 0x23, 0x96, 0xe4, 0x41, 0x44, 0x7a, 0x65, 0x3d, 0x9b, 0xb8, 0x5a, 0x5f,
-	0x1b, 0x74, 0xe8, 0x41, 0x93, 0x67, 0x00, 0xaa, 0xbe, 0x09, 0x63, 0xe6, 0xea, 0x78, 0x7a, 0xd2,
-	0x21, 0xcf, 0xe1, 0xb1, 0x52, 0xdf, 0x58, 0x26, 0x22, 0x9e, 0xb8, 0x06, 0x5a, 0xaa, 0x4d, 0xf2,
-	0x1a, 0xba, 0xaa, 0x31, 0x61, 0x62, 0x99, 0x45, 0x69, 0x2e, 0x9d, 0x26, 0x3a, 0x9b, 0x07, 0xe4,
-	0x2b, 0x74, 0x47, 0xdb, 0x2d, 0xff, 0xc1, 0x56, 0x63, 0x1e, 0xc7, 0x61, 0xb2, 0x9a, 0x4d, 0x84,
-	0x6b, 0xf5, 0x8d, 0x81, 0xe3, 0xbf, 0x1c, 0x16, 0x9f, 0x53, 0xc2, 0x0f, 0x1b, 0xce, 0x69, 0x92,
-	0x67, 0x7b, 0xda, 0xbc, 0x81, 0x3c, 0x85, 0xce, 0x22, 0x8a, 0x99, 0xc8, 0xc3, 0x38, 0x75, 0xed,
-	0xbe, 0x36, 0x30, 0xe8, 0xb1, 0xd1, 0x9b, 0xc0, 0xd5, 0xff, 0xaf, 0x22, 0x17, 0x60, 0x6c, 0xd8,
-	0x1e, 0x93, 0x31, 0xa8, 0x2c, 0xc9, 0x25, 0x58, 0x77, 0xe1, 0xf6, 0x7b, 0x99, 0x87, 0x12, 0x1f,
-	0xf4, 0x77, 0x9a, 0xf7, 0x19, 0x1c, 0x45, 0x76, 0xcd, 0xc2, 0x3b, 0x76, 0x6f, 0xb2, 0x15, 0x1c,
-	0xbd, 0x86, 0xe3, 0xfd, 0xd2, 0xa0, 0xa3, 0xac, 0x94, 0xed, 0xe4, 0x40, 0xca, 0x76, 0x87, 0x4b,
-	0x94, 0x20, 0x04, 0xcc, 0x4f, 0x19, 0x8f, 0x0b, 0x0a, 0xac, 0xc9, 0x39, 0xe8, 0x0b, 0x5e, 0x2c,
-	0x41, 0x5f, 0x70, 0x39, 0xe5, 0xf0, 0x3d, 0x98, 0xb8, 0x41, 0x8f, 0x0d, 0xe2, 0x42, 0x7b, 0xcc,
-	0x93, 0x9c, 0x25, 0xb9, 0x6b, 0xe1, 0xbf, 0x94, 0xf2, 0xfe, 0xb0, 0xbc, 0xdf, 0x5a, 0xf9, 0x2c,
-	0x28, 0x13, 0x29, 0xb9, 0x02, 0x5b, 0xfe, 0x3d, 0xf0, 0x15, 0xea, 0x41, 0x80, 0x27, 0x08, 0x66,
-	0x15, 0xc1, 0x85, 0xf6, 0x34, 0xcb, 0xc6, 0x7c, 0xc5, 0x10, 0xce, 0xa2, 0xa5, 0x94, 0xf3, 0xa6,
-	0x59, 0x36, 0x17, 0x6b, 0x24, 0xeb, 0xd0, 0x42, 0x55, 0xa1, 0xdb, 0x75, 0xe8, 0x5b, 0xb0, 0x47,
-	0xcb, 0x8d, 0xf4, 0x5d, 0x82, 0x35, 0x17, 0xeb, 0x63, 0x9c, 0x28, 0x24, 0xed, 0x4c, 0x7c, 0xd9,
-	0x20, 0xed, 0x19, 0xc5, 0x5a, 0xee, 0x5e, 0x8e, 0x51, 0xb8, 0x46, 0x63, 0x86, 0x59, 0x9f, 0xf1,
-	0xf7, 0xb0, 0x36, 0xe9, 0xf5, 0x40, 0x0f, 0x22, 0x1c, 0xe2, 0xf8, 0x17, 0xf5, 0x97, 0x1b, 0xb4,
-	0xa8, 0x1e, 0x44, 0xe4, 0x15, 0x58, 0xf8, 0x56, 0x70, 0xac, 0xe3, 0x3f, 0xa9, 0xda, 0xf0, 0x28,
-	0x68, 0x51, 0xe5, 0x21, 0x2f, 0xc0, 0xa0, 0x6c, 0x87, 0x38, 0x8e, 0xdf, 0xad, 0x5a, 0x29, 0xdb,
-	0x05, 0x2d, 0x2a, 0xcf, 0xc9, 0x00, 0x4c, 0xb9, 0x01, 0xc4, 0x73, 0x7c, 0x52, 0xf7, 0x89, 0x34,
-	0x68, 0x51, 0x74, 0x10, 0x0f, 0x8c, 0xd1, 0x72, 0x83, 0xf9, 0x3a, 0xfe, 0x79, 0x69, 0x54, 0x31,
-	0xc9, 0xdb, 0x46, 0xcb, 0xcd, 0x31, 0x2d, 0xfb, 0x24, 0xad, 0x8f, 0x1d, 0x68, 0xcf, 0x99, 0x10,
-	0xe1, 0x9a, 0xf9, 0x23, 0x30, 0x6f, 0xe4, 0x5a, 0xde, 0x83, 0x53, 0xb4, 0xae, 0x39, 0x4f, 0x49,
-	0x8d, 0x6f, 0x2e, 0xd6, 0xbd, 0x66, 0xcb, 0x6b, 0x0d, 0xb4, 0x37, 0xda, 0xad, 0x8d, 0x3f, 0x4f,
-	0x6f, 0xff, 0x05, 0x00, 0x00, 0xff, 0xff, 0x9e, 0x63, 0xfd, 0x06, 0xae, 0x04, 0x00, 0x00,
+	// 1375 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x58, 0xdb, 0x6e, 0x1b, 0x45,
+	0x18, 0xf6, 0x7a, 0xed, 0xd8, 0xfe, 0x37, 0x4d, 0xd3, 0x21, 0x0a, 0x2b, 0xab, 0xaa, 0xcc, 0x50,
+	0x84, 0x51, 0x51, 0x01, 0x53, 0xc4, 0xe1, 0xa6, 0xb8, 0x8e, 0x5b, 0xa7, 0xc4, 0xc5, 0x4c, 0x52,
+	0xb8, 0xde, 0xd8, 0x13, 0x77, 0xe5, 0xf5, 0x8e, 0x33, 0x33, 0x0e, 0x0d, 0x6f, 0x80, 0x10, 0xcf,
+	0x00, 0x4f, 0xc0, 0x23, 0xf0, 0x10, 0x5c, 0x73, 0xc1, 0x83, 0x70, 0x81, 0x66, 0x66, 0x8f, 0x5e,
+	0x3b, 0x29, 0x02, 0x24, 0xae, 0xbc, 0xff, 0xf7, 0x7f, 0x73, 0xf8, 0xcf, 0x23, 0xc3, 0xf6, 0x94,
+	0x05, 0xde, 0x77, 0x97, 0xf7, 0x17, 0x9c, 0x49, 0x86, 0xb6, 0x8c, 0x84, 0x7f, 0xb7, 0xa1, 0xde,
+	0x0b, 0x7c, 0x1a, 0xca, 0x81, 0x8f, 0x9a, 0xf1, 0xf7, 0xe1, 0x81, 0x6b, 0xb5, 0xac, 0x76, 0x83,
+	0x24, 0x32, 0xba, 0x03, 0x60, 0xbe, 0x9f, 0x79, 0x73, 0xea, 0x96, 0xb5, 0x36, 0x83, 0xa0, 0xbb,
+	0x70, 0xc3, 0x48, 0x5f, 0x53, 0x2e, 0x7c, 0x16, 0xba, 0xb6, 0xa6, 0xe4, 0x41, 0xf4, 0x2e, 0xdc,
+	0x32, 0xc0, 0x01, 0x15, 0x63, 0xee, 0x2f, 0xa4, 0x62, 0x56, 0x34, 0xb3, 0xa8, 0x40, 0xcf, 0xe1,
+	0x56, 0x37, 0x08, 0xd8, 0xb7, 0x74, 0xd2, 0x63, 0xf3, 0xb9, 0x17, 0x4e, 0x0e, 0x0f, 0x84, 0x5b,
+	0x6d, 0xd9, 0x6d, 0xa7, 0xf3, 0xf6, 0xfd, 0xc8, 0x9c, 0xf8, 0xf2, 0xf7, 0x0b, 0xcc, 0x7e, 0x28,
+	0xf9, 0x25, 0x29, 0xee, 0x80, 0x6e, 0x43, 0xe3, 0xc4, 0x9f, 0x53, 0x21, 0xbd, 0xf9, 0xc2, 0xdd,
+	0x6a, 0x59, 0x6d, 0x9b, 0xa4, 0x80, 0xba, 0xe2, 0xf1, 0xf2, 0x54, 0x5d, 0xe2, 0x94, 0x8e, 0x38,
+	0x15, 0x34, 0x1c, 0x53, 0xb7, 0xd6, 0xb2, 0xda, 0x75, 0x52, 0x54, 0xa0, 0x16, 0x38, 0xc7, 0x94,
+	0x5f, 0xf8, 0x63, 0xaa, 0xfd, 0x52, 0xd7, 0xa6, 0x64, 0x21, 0xf4, 0x11, 0x38, 0x3d, 0x4e, 0x27,
+	0x34, 0x94, 0xbe, 0x17, 0x08, 0xb7, 0xd1, 0xb2, 0xda, 0x4e, 0xe7, 0xb5, 0xe4, 0xfa, 0xa9, 0x8a,
+	0x64, 0x79, 0xcd, 0x03, 0xd8, 0x5f, 0x6f, 0x11, 0xda, 0x05, 0x7b, 0x46, 0x2f, 0x75, 0x80, 0x6c,
+	0xa2, 0x3e, 0xd1, 0x1e, 0x54, 0x2f, 0xbc, 0x60, 0x19, 0x87, 0xc5, 0x08, 0x9f, 0x95, 0x3f, 0xb1,
+	0xf0, 0x8f, 0x56, 0xee, 0x74, 0xb4, 0x0f, 0x5b, 0x43, 0x2a, 0x5f, 0xb0, 0x49, 0x14, 0xdf, 0x48,
+	0x52, 0x3b, 0x9c, 0xb0, 0x19, 0x0d, 0xe3, 0x1d, 0xb4, 0xa0, 0xd0, 0x67, 0x4c, 0x99, 0x6f, 0x62,
+	0x69, 0x84, 0xbc, 0xfb, 0x2a, 0xab, 0xee, 0xbb, 0x0d, 0x8d, 0x63, 0x7f, 0x1a, 0x7a, 0x72, 0xc9,
+	0xa9, 0x5b, 0xd5, 0xeb, 0x52, 0x00, 0x3f, 0x01, 0xc7, 0x04, 0xec, 0x88, 0x7a, 0x17, 0xf4, 0xca,
+	0x84, 0xcb, 0x1d, 0x53, 0x5e, 0x39, 0x06, 0xff, 0x50, 0x86, 0x86, 0xa1, 0x12, 0x7a, 0xae, 0x2e,
+	0x4a, 0xe8, 0x79, 0xb2, 0x89, 0x11, 0x10, 0x82, 0xca, 0x63, 0xce, 0xe6, 0x91, 0x4d, 0xfa, 0x1b,
+	0xed, 0x40, 0xf9, 0x84, 0x45, 0xf6, 0x94, 0x4f, 0x98, 0x3a, 0x25, 0xf1, 0x6f, 0x6c, 0x4c, 0x02,
+	0x20, 0x17, 0x6a, 0x3d, 0x16, 0x4a, 0x1a, 0xca, 0xc8, 0x94, 0x58, 0xbc, 0x26, 0x87, 0x9a, 0x50,
+	0x27, 0x6c, 0x29, 0xe9, 0x17, 0xf4, 0x52, 0xa7, 0x4e, 0x83, 0x24, 0xb2, 0x5a, 0xf9, 0x88, 0x33,
+	0x6f, 0x32, 0xf6, 0x84, 0xd4, 0xf9, 0x52, 0x27, 0x29, 0xa0, 0x02, 0xf4, 0xd5, 0x92, 0xf1, 0xe5,
+	0x5c, 0x27, 0x4a, 0x95, 0x44, 0x52, 0x7c, 0x1e, 0x5b, 0xca, 0xa1, 0x70, 0x21, 0x3d, 0x4f, 0x03,
+	0xf8, 0x0f, 0x2b, 0xae, 0x4e, 0x42, 0xc5, 0x42, 0x6d, 0xa2, 0x7e, 0x13, 0x7f, 0x44, 0xd2, 0x2b,
+	0x39, 0x24, 0x63, 0x72, 0x25, 0x6f, 0xb2, 0x0b, 0xb5, 0x3e, 0xe7, 0x3d, 0x36, 0x31, 0x71, 0xad,
+	0x92, 0x58, 0x54, 0xe7, 0xf5, 0x39, 0x1f, 0x8a, 0xa9, 0xf6, 0x44, 0x83, 0x44, 0x52, 0xde, 0x49,
+	0xb5, 0x62, 0xa1, 0xd5, 0x08, 0x15, 0xcb, 0x40, 0x0a, 0xb7, 0xae, 0x6b, 0x1a, 0xc5, 0x45, 0x71,
+	0xe2, 0xf1, 0x29, 0xd5, 0xa6, 0x90, 0x98, 0x82, 0x25, 0x40, 0x0a, 0x5f, 0x99, 0x38, 0x19, 0x0b,
+	0xca, 0x1b, 0x2d, 0xb0, 0x37, 0x59, 0x50, 0xc9, 0x5a, 0x80, 0x7f, 0xb6, 0x60, 0xab, 0x3b, 0x9e,
+	0x29, 0x63, 0xf6, 0xa0, 0x3a, 0x14, 0xd3, 0x34, 0xc7, 0xb4, 0xa0, 0x5c, 0x7a, 0x28, 0xbe, 0x9c,
+	0xe9, 0x93, 0xea, 0x44, 0x7f, 0xab, 0x02, 0x55, 0x3b, 0x19, 0x9f, 0xda, 0x05, 0x47, 0x14, 0x4a,
+	0x66, 0xb3, 0x63, 0x31, 0x6c, 0x13, 0x2a, 0xf9, 0x65, 0xf7, 0x4c, 0x52, 0x3e, 0x14, 0x51, 0xa2,
+	0xe5, 0x30, 0xfc, 0xab, 0x05, 0x3b, 0xc6, 0xf6, 0xa4, 0x29, 0xfd, 0xf7, 0x7d, 0x7c, 0x0f, 0xaa,
+	0xfd, 0x8b, 0x34, 0x47, 0x8c, 0x60, 0xf2, 0xce, 0x13, 0x2c, 0x8c, 0xaa, 0x25, 0x92, 0xae, 0x2e,
+	0x16, 0x7c, 0x17, 0x2a, 0x23, 0x3f, 0x5c, 0x71, 0x92, 0xb5, 0xca, 0x7a, 0x0a, 0x95, 0x11, 0x0b,
+	0xa7, 0xea, 0x7e, 0x8a, 0xbd, 0xca, 0xcc, 0x83, 0xd7, 0x34, 0x8f, 0x37, 0xa0, 0x91, 0x74, 0x72,
+	0xd3, 0xfa, 0x16, 0xfe, 0x38, 0x8e, 0xab, 0x16, 0xf0, 0x9b, 0xe0, 0x3c, 0x0f, 0xc5, 0x35, 0xa4,
+	0x19, 0xd4, 0x46, 0xcb, 0xd3, 0xc0, 0x17, 0x2f, 0xd6, 0x13, 0xd6, 0x16, 0x5c, 0x26, 0x3d, 0xed,
+	0x2b, 0x7a, 0xca, 0x6a, 0x96, 0xe0, 0x11, 0xec, 0x1c, 0xf9, 0x42, 0x9a, 0x38, 0x08, 0xd5, 0xf5,
+	0xee, 0x00, 0xa8, 0x90, 0x8d, 0x38, 0x3d, 0xf3, 0x5f, 0x46, 0x07, 0x67, 0x10, 0x1d, 0xea, 0x74,
+	0x6e, 0x96, 0x5b, 0x76, 0xdb, 0x26, 0x19, 0x04, 0xff, 0x64, 0xc7, 0xb9, 0x70, 0x18, 0x9e, 0xb1,
+	0xff, 0xdd, 0xf4, 0xff, 0x66, 0xf3, 0xf4, 0x7f, 0x27, 0x3f, 0xfd, 0xd5, 0xf5, 0xff, 0xc6, 0xfc,
+	0xbf, 0x03, 0x40, 0xe8, 0x9c, 0x49, 0xda, 0x9d, 0x4c, 0x78, 0xd4, 0xb2, 0x32, 0x88, 0x9a, 0xe9,
+	0x3d, 0x16, 0x86, 0x74, 0x2c, 0xe9, 0xa4, 0x2b, 0xa3, 0xc6, 0x95, 0x85, 0xae, 0x9f, 0xfa, 0xff,
+	0xd2, 0xf8, 0x7e, 0x08, 0x37, 0x73, 0x31, 0x17, 0xba, 0x6b, 0x46, 0xa2, 0x6b, 0xe5, 0xbb, 0x66,
+	0xea, 0x0b, 0x12, 0x53, 0xf0, 0x2f, 0x16, 0xec, 0x1e, 0x50, 0x6f, 0x72, 0x44, 0xa5, 0xa4, 0xfc,
+	0xb1, 0x1f, 0x48, 0xca, 0x37, 0x74, 0xb2, 0x6c, 0xf8, 0xcb, 0x2b, 0xe1, 0x4f, 0xe6, 0xab, 0x9d,
+	0x9d, 0xaf, 0x7b, 0x50, 0x3d, 0xf6, 0xd5, 0xf3, 0xc0, 0xe4, 0xaa, 0x11, 0x14, 0xfa, 0x3c, 0x94,
+	0x7e, 0xa0, 0x7b, 0x80, 0x4d, 0x8c, 0xa0, 0xd0, 0x23, 0x7f, 0xee, 0x4b, 0xed, 0xee, 0x2a, 0x31,
+	0x82, 0xf2, 0x45, 0x37, 0x08, 0xa2, 0xd7, 0x95, 0xfa, 0xc4, 0x6d, 0xd8, 0x7d, 0x42, 0x65, 0x7a,
+	0xe5, 0x68, 0xba, 0x17, 0xef, 0x8b, 0xff, 0xb4, 0x00, 0x52, 0xde, 0x06, 0xa3, 0x5c, 0xa8, 0x0d,
+	0xc5, 0xf4, 0xe4, 0x72, 0x11, 0x3b, 0x37, 0x16, 0x37, 0x98, 0x14, 0x17, 0x6c, 0xa5, 0x30, 0x21,
+	0xab, 0xd9, 0x09, 0xa9, 0x1a, 0xb0, 0x4f, 0x45, 0x64, 0x4c, 0x2c, 0xaa, 0x02, 0x3e, 0xf2, 0x84,
+	0xec, 0x73, 0xce, 0x78, 0x34, 0xf7, 0x53, 0x40, 0x9d, 0xd8, 0x9d, 0x4c, 0xe8, 0x44, 0xa7, 0x8b,
+	0x4d, 0x8c, 0xa0, 0xd6, 0xf4, 0x5f, 0x2e, 0x7c, 0x4e, 0x45, 0x57, 0xea, 0x99, 0x6f, 0x93, 0x14,
+	0xc8, 0x36, 0x0b, 0xc8, 0x35, 0x0b, 0xfc, 0x18, 0x76, 0x52, 0xeb, 0x55, 0x92, 0xa0, 0x07, 0xe0,
+	0xa4, 0x48, 0x21, 0x3b, 0x52, 0x15, 0xc9, 0xd2, 0x94, 0xc3, 0x33, 0x2a, 0x3d, 0x6c, 0xd5, 0x4d,
+	0x7b, 0x6c, 0x19, 0xca, 0x28, 0x49, 0x8d, 0x80, 0xbf, 0xaf, 0xc4, 0x4f, 0x2e, 0x35, 0xd2, 0x30,
+	0x94, 0x07, 0xbe, 0x26, 0x38, 0x9d, 0xdd, 0xd5, 0xc7, 0xf8, 0xa0, 0x44, 0xca, 0x03, 0x1f, 0xdd,
+	0x83, 0xaa, 0x7e, 0xe7, 0x69, 0xdf, 0x67, 0x1f, 0xbd, 0xe9, 0x13, 0x70, 0x50, 0x22, 0x86, 0x83,
+	0xde, 0x02, 0x9b, 0xd0, 0x73, 0x1d, 0x0e, 0xa7, 0x73, 0x2b, 0x4f, 0x25, 0xf4, 0x7c, 0x50, 0x22,
+	0x4a, 0x8f, 0xda, 0x50, 0x51, 0x75, 0xa0, 0x23, 0x54, 0x48, 0x7e, 0xa5, 0x19, 0x94, 0x88, 0x66,
+	0x20, 0x0c, 0x76, 0x77, 0x3c, 0xd3, 0x81, 0x73, 0x3a, 0x3b, 0x31, 0xd1, 0x4c, 0x73, 0xb5, 0x5b,
+	0x77, 0x3c, 0x43, 0x0f, 0xa0, 0x9e, 0x7b, 0xe3, 0x3b, 0x9d, 0xfd, 0xfc, 0x8e, 0xb1, 0x76, 0x50,
+	0x22, 0x09, 0x13, 0x61, 0x33, 0xb1, 0x74, 0x20, 0x9d, 0xce, 0x76, 0xbc, 0x42, 0x61, 0xea, 0x74,
+	0xf5, 0xab, 0x39, 0x2c, 0x9c, 0xba, 0x8d, 0x15, 0x0e, 0x8b, 0x38, 0x6a, 0x96, 0x7d, 0x90, 0x99,
+	0x43, 0x2e, 0xe4, 0x0d, 0x4f, 0x14, 0x83, 0x12, 0x49, 0x59, 0xe8, 0xe3, 0xdc, 0x5c, 0x72, 0x9d,
+	0xbc, 0x63, 0x33, 0xaa, 0x41, 0x89, 0x64, 0x99, 0xe8, 0x5e, 0x32, 0xab, 0xdc, 0x6d, 0xbd, 0xe8,
+	0x66, 0x72, 0x25, 0x03, 0x0f, 0x4a, 0x24, 0x3b, 0xcd, 0x4c, 0x31, 0x6d, 0x65, 0x8a, 0xe9, 0x51,
+	0x03, 0x6a, 0x43, 0x2a, 0x84, 0x37, 0xa5, 0x9d, 0xdf, 0x6c, 0xa8, 0x3c, 0x53, 0x2f, 0x94, 0x4f,
+	0xc1, 0x89, 0xb0, 0x23, 0xc6, 0x16, 0x68, 0x25, 0x6e, 0x43, 0x31, 0x6d, 0x16, 0x21, 0x5c, 0x6a,
+	0x5b, 0xef, 0x5b, 0xe8, 0x3d, 0xa8, 0xf4, 0xbc, 0x20, 0x40, 0xc5, 0x58, 0x37, 0xd7, 0x84, 0x15,
+	0x97, 0xd0, 0xe7, 0xe0, 0x64, 0xba, 0x21, 0x4a, 0x22, 0x95, 0x1f, 0x8b, 0xcd, 0xd7, 0xd7, 0xe2,
+	0x7a, 0x87, 0xbe, 0xe9, 0xa7, 0x99, 0xfc, 0x47, 0x6e, 0xb1, 0x40, 0x4c, 0x9b, 0x6c, 0xee, 0x17,
+	0x35, 0x6a, 0x31, 0x2e, 0xa1, 0x87, 0x70, 0x23, 0xd7, 0xa4, 0xd2, 0x4d, 0x56, 0x7b, 0x57, 0x73,
+	0x4d, 0xfd, 0xe1, 0x12, 0x7a, 0x0a, 0x88, 0xd0, 0xf3, 0x25, 0x5d, 0xd2, 0x57, 0xbb, 0xca, 0x1a,
+	0x8d, 0x29, 0x55, 0x5c, 0x42, 0x03, 0xd8, 0x1d, 0x2d, 0xf9, 0xf4, 0x9f, 0xef, 0x74, 0xba, 0xa5,
+	0xff, 0x1a, 0xf8, 0xf0, 0xaf, 0x01, 0x00, 0x8c, 0x3d, 0x6f, 0x06, 0x2a, 0x10, 0x00, 0x00,
 }
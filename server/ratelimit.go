@@ -0,0 +1,160 @@
+package main
+
+import (
+	"github.com/dato-live/golazy/server/config"
+	"github.com/dato-live/golazy/server/store/types"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How often to forget the buckets of the clients which have not sent messages for a while
+const rateLimitPruneInterval = time.Minute
+
+// 限流范围，用于提示客户端被哪个限制拒绝
+const (
+	rateLimitSession = "session"
+	rateLimitClient  = "client"
+	rateLimitGlobal  = "global"
+)
+
+// Prefix of the keys limiting the callers by address, keeps them apart from ClientIDs
+const rateLimitIPPrefix = "ip:"
+
+// ipLimitKey returns the key limiting the messages of the callers from host.
+func ipLimitKey(host string) string {
+	return rateLimitIPPrefix + host
+}
+
+// tokenBucket allows 'rate' messages per second on average, and bursts of up to 'burst' messages.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit config.RateLimit, now time.Time) *tokenBucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{rate: limit.Rate, burst: float64(limit.Burst), tokens: float64(limit.Burst), last: now}
+}
+
+// refill adds the tokens accumulated since the last refill, and returns how long to wait for a token.
+func (b *tokenBucket) refill(now time.Time) time.Duration {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// full checks whether the bucket has refilled completely, it then behaves as a new bucket.
+func (b *tokenBucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
+
+// RateLimiter limits the requests and responses sent by the clients per session, per ClientID and globally.
+type RateLimiter struct {
+	lock sync.Mutex
+
+	conf   config.RateLimitConfig
+	global *tokenBucket
+	// Buckets of the clients, kept across reconnections
+	clients   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+// NewRateLimiter initializes a rate limiter with the configured limits.
+func NewRateLimiter(conf config.RateLimitConfig) *RateLimiter {
+	now := types.TimeNow()
+	return &RateLimiter{
+		conf:      conf,
+		global:    newTokenBucket(conf.Global, now),
+		clients:   make(map[string]*tokenBucket),
+		lastPrune: now,
+	}
+}
+
+// limitOf returns the limit of the client: its override if it has one, otherwise the default limit.
+// Overrides are configured by ClientID and do not apply to the callers limited by address.
+func (rl *RateLimiter) limitOf(clientID string, limit config.RateLimit) config.RateLimit {
+	if strings.HasPrefix(clientID, rateLimitIPPrefix) {
+		return limit
+	}
+	if override, ok := rl.conf.Overrides[clientID]; ok {
+		return override
+	}
+	return limit
+}
+
+// Allow takes a token for a message of the client clientID sent on the session sess, nil for the
+// messages not sent on a session. If one of the limits is reached, no token is taken and Allow returns
+// how long to wait before sending the message again and the scope of the limit.
+func (rl *RateLimiter) Allow(sess *Session, clientID string) (time.Duration, string) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	now := types.TimeNow()
+	rl.prune(now)
+
+	var buckets []*tokenBucket
+	var scopes []string
+	if sess != nil {
+		if sess.rateLimit == nil {
+			sess.rateLimit = newTokenBucket(rl.limitOf(clientID, rl.conf.Session), now)
+		}
+		if sess.rateLimit != nil {
+			buckets, scopes = append(buckets, sess.rateLimit), append(scopes, rateLimitSession)
+		}
+	}
+	bucket, ok := rl.clients[clientID]
+	if !ok {
+		bucket = newTokenBucket(rl.limitOf(clientID, rl.conf.Client), now)
+		if bucket != nil {
+			rl.clients[clientID] = bucket
+		}
+	}
+	if bucket != nil {
+		buckets, scopes = append(buckets, bucket), append(scopes, rateLimitClient)
+	}
+	if rl.global != nil {
+		buckets, scopes = append(buckets, rl.global), append(scopes, rateLimitGlobal)
+	}
+
+	//所有限制均有令牌时才消耗令牌，被拒绝的消息不占用配额
+	var wait time.Duration
+	var scope string
+	for i, b := range buckets {
+		if w := b.refill(now); w > wait {
+			wait, scope = w, scopes[i]
+		}
+	}
+	if wait > 0 {
+		return wait, scope
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0, ""
+}
+
+// prune forgets the buckets of the clients which have refilled completely.
+func (rl *RateLimiter) prune(now time.Time) {
+	if now.Sub(rl.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	rl.lastPrune = now
+	for clientID, b := range rl.clients {
+		if b.full(now) {
+			delete(rl.clients, clientID)
+		}
+	}
+}
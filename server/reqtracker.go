@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dato-live/golazy/server/store"
+	"github.com/dato-live/golazy/server/store/types"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+const (
+	// How often to look for requests whose deadline has passed
+	requestCheckInterval = time.Second
+	// How long a responded request is remembered, to reject duplicated responses
+	respondedRetention = 5 * time.Minute
+)
+
+// reqKey identifies a request: ReqID is chosen by the sender, so it is only unique per sender.
+type reqKey struct {
+	from  string
+	reqID string
+}
+
+// trackedReq is a request delivered to its target and waiting for the response.
+type trackedReq struct {
+	// ID of the stored message delivering the request
+	msgID string
+	// Target of the request as addressed by the sender: a client or a service
+	to string
+	// ClientID of the session the request is delivered to, the only one allowed to respond
+	target string
+
+	sentAt time.Time
+	// Time after which the request is no longer waited for, the sender then receives a timeout response
+	deadline time.Time
+
+	// Set once responded or timed out, the request is then kept to reject later responses
+	respondedAt time.Time
+	timedOut    bool
+}
+
+// ReqTracker holds the requests routed to their targets with a timeout, to answer them once the timeout
+// passes, and the requests responded recently, to reject duplicated responses. The responses to the other
+// requests are matched with the stored requests.
+type ReqTracker struct {
+	lock sync.Mutex
+
+	reqs map[reqKey]*trackedReq
+}
+
+// NewReqTracker initializes a request tracker.
+func NewReqTracker() *ReqTracker {
+	return &ReqTracker{
+		reqs: make(map[reqKey]*trackedReq),
+	}
+}
+
+// reqDeadline returns the time by which the request must be responded, zero if the request has no timeout.
+func reqDeadline(req *DMClientReq) time.Time {
+	if req.TimeoutMs <= 0 {
+		return time.Time{}
+	}
+	return types.TimeNow().Add(time.Duration(req.TimeoutMs) * time.Millisecond)
+}
+
+// Add starts tracking the request delivered by the message msgID to the client 'target', if it has a timeout.
+// 'target' is the target of the request as addressed by the sender if it is offline.
+func (rt *ReqTracker) Add(req *DMClientReq, msgID string, target string) {
+	r := &trackedReq{msgID: msgID, to: req.To, target: target, sentAt: types.TimeNow(), deadline: reqDeadline(req)}
+	if r.deadline.IsZero() {
+		//未设置超时时间的请求无需在内存中等待，响应时按存储的请求匹配
+		return
+	}
+
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	rt.reqs[reqKey{from: req.From, reqID: req.ReqID}] = r
+}
+
+// Redeliver records that the stored request is sent again to the client req.To, which may be another
+// member of the service than the one it was first assigned to. Requests with a timeout not tracked yet,
+// as the ones stored before the server restarted, are tracked from now on.
+func (rt *ReqTracker) Redeliver(req *types.ReqReceived) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	key := reqKey{from: req.From, reqID: req.ReqID}
+	if r, ok := rt.reqs[key]; ok {
+		if r.respondedAt.IsZero() {
+			r.msgID, r.target = req.MsgID, req.To
+		}
+		return
+	}
+	if req.Deadline.IsZero() {
+		return
+	}
+	rt.reqs[key] = &trackedReq{msgID: req.MsgID, to: req.To, target: req.To, sentAt: req.Added, deadline: req.Deadline}
+}
+
+// Respond matches the response sent by the client 'responder' with the request it answers, and marks
+// the request as responded. Requests which are not tracked are looked up in the store. Returns the request,
+// or a negative ack code and message if the response answers no request waiting for it.
+func (rt *ReqTracker) Respond(resp *DMClientResp, responder string) (*trackedReq, int32, string) {
+	rt.lock.Lock()
+	r, ok := rt.reqs[reqKey{from: resp.To, reqID: resp.RespID}]
+	if !ok {
+		rt.lock.Unlock()
+		return rt.respondStored(resp, responder)
+	}
+	defer rt.lock.Unlock()
+
+	switch {
+	case r.target != responder:
+		return nil, AckErrResponderMismatch, fmt.Sprintf("Responder mismatch, request [%s] of [%s] was not sent to [%s]", resp.RespID, resp.To, responder)
+	case r.timedOut:
+		return nil, AckErrDuplicatedResponse, fmt.Sprintf("Duplicated response, request [%s] of [%s] already timed out", resp.RespID, resp.To)
+	case !r.respondedAt.IsZero():
+		return nil, AckErrDuplicatedResponse, fmt.Sprintf("Duplicated response, request [%s] of [%s] is already responded", resp.RespID, resp.To)
+	}
+	r.respondedAt = types.TimeNow()
+	return r, AckErrNone, ""
+}
+
+// respondStored matches a response with the stored request it answers, for the requests without a timeout
+// and the ones no longer tracked, and tracks the request as responded to reject duplicated responses.
+// The stored request is marked as responded by ackRespondedReq, so duplicates are rejected after it is forgotten.
+func (rt *ReqTracker) respondStored(resp *DMClientResp, responder string) (*trackedReq, int32, string) {
+	req, err := store.MsgObj.GetReqByReqID(resp.To, responder, resp.RespID)
+	if err != nil {
+		return nil, AckErrOrphanResponse, fmt.Sprintf("Orphan response, no request [%s] of [%s] sent to [%s] is waiting for response", resp.RespID, resp.To, responder)
+	}
+	switch {
+	case req.Status == types.StatusResponded:
+		return nil, AckErrDuplicatedResponse, fmt.Sprintf("Duplicated response, request [%s] of [%s] is already responded", resp.RespID, resp.To)
+	case req.Status == types.StatusTimeout || reqExpired(req):
+		return nil, AckErrDuplicatedResponse, fmt.Sprintf("Duplicated response, request [%s] of [%s] already timed out", resp.RespID, resp.To)
+	case req.Status == types.StatusDead:
+		//死信请求未送达或已放弃，重新投递前不接受其响应
+		return nil, AckErrOrphanResponse, fmt.Sprintf("Orphan response, request [%s] of [%s] is a dead letter", resp.RespID, resp.To)
+	}
+
+	now := types.TimeNow()
+	r := &trackedReq{msgID: req.MsgID, to: req.To, target: responder, sentAt: req.Added, deadline: req.Deadline, respondedAt: now}
+
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	key := reqKey{from: resp.To, reqID: resp.RespID}
+	if _, ok := rt.reqs[key]; ok {
+		//并发收到的重复响应
+		return nil, AckErrDuplicatedResponse, fmt.Sprintf("Duplicated response, request [%s] of [%s] is already responded", resp.RespID, resp.To)
+	}
+	rt.reqs[key] = r
+	return r, AckErrNone, ""
+}
+
+// expire returns the requests whose timeout has passed and marks them as timed out. The requests
+// responded or timed out long ago are forgotten.
+func (rt *ReqTracker) expire(now time.Time) map[reqKey]*trackedReq {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+
+	expired := make(map[reqKey]*trackedReq)
+	for key, r := range rt.reqs {
+		switch {
+		case !r.respondedAt.IsZero():
+			if now.Sub(r.respondedAt) > respondedRetention {
+				delete(rt.reqs, key)
+			}
+		case r.deadline.After(now):
+		default:
+			r.respondedAt, r.timedOut = now, true
+			expired[key] = r
+		}
+	}
+	return expired
+}
+
+// ExpireRequestLoop answers the senders of the requests not responded by their deadline with a
+// timeout response, and stops retrying these requests.
+func (rt *ReqTracker) ExpireRequestLoop(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(requestCheckInterval):
+			for key, req := range rt.expire(types.TimeNow()) {
+				logger.Warn(fmt.Sprintf("[Request Timeout] Target [%s] did not respond to request [%s] of [%s] in time", req.to, key.reqID, key.from))
+				expireReq(req.msgID)
+				now := types.TimeNow()
+				sendResp(&DMClientResp{
+					RespID:    key.reqID,
+					From:      req.to,
+					To:        key.from,
+					ErrCode:   RespErrTimeout,
+					ErrMsg:    fmt.Sprintf("Request timeout, target [%s] did not respond in time", req.to),
+					Timestamp: &now,
+				}, nil)
+			}
+		}
+	}
+}
+
+// reqExpired checks whether the deadline of a stored request has passed.
+func reqExpired(req *types.ReqReceived) bool {
+	return !req.Deadline.IsZero() && !req.Deadline.After(types.TimeNow())
+}
+
+// expireReq marks the stored request as timed out, so that it is not sent again, and no longer counts
+// it as waiting for the response of its target.
+func expireReq(msgID string) {
+	req, err := store.MsgObj.GetReqByMsgID(msgID)
+	if err != nil {
+		logger.Error("GetReqByMsgID failed", zap.String("MsgID", msgID), zap.Error(err))
+		return
+	}
+	releaseRequest(req.To, msgID)
+	saveReqStatus(req, func(req *types.ReqReceived) bool {
+		if req.Status == types.StatusDead || req.Status == types.StatusTimeout || req.Status == types.StatusResponded {
+			return false
+		}
+		req.Status = types.StatusTimeout
+		return true
+	})
+}
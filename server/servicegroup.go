@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dato-live/golazy/server/auth"
+	"github.com/dato-live/golazy/server/store"
+	"github.com/dato-live/golazy/server/store/types"
+	"hash/fnv"
+	"sync"
+)
+
+// Serializes claiming the requests stored for a service, so that each of them is replayed to one member only
+var serviceClaimLock sync.Mutex
+
+// checkService checks whether the client clientID may join the service: the ClientID must not be the
+// name of another service, the service name must not be the ClientID of another online client, and if
+// members are configured for the service the client must be one of them. With client authentication
+// enabled only configured services may be joined. Returns the reason if the client is rejected.
+func checkService(service string, clientID string) string {
+	//请求按目标查找时优先匹配ClientID，与服务同名的客户端会截获发往该服务的请求
+	if clientID != service && globals.sessionStore.IsService(clientID) {
+		return fmt.Sprintf("ClientID [%s] is the name of a service", clientID)
+	}
+	if service == "" {
+		return ""
+	}
+	if service != clientID && globals.sessionStore.GetByClientID(service) != nil {
+		return fmt.Sprintf("service name [%s] is the ClientID of another client", service)
+	}
+	patterns, ok := globals.configs.ServiceMembers[service]
+	if !ok {
+		if auth.Enabled() {
+			return fmt.Sprintf("service [%s] is not configured in service_members", service)
+		}
+		return ""
+	}
+	for _, pattern := range patterns {
+		if aclMatch(pattern, clientID) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("client [%s] is not a member of service [%s]", clientID, service)
+}
+
+// AddService records that a client joined the service, so that requests sent to it while it has no
+// member online are stored for the service.
+func (ss *SessionStore) AddService(service string) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	if ss.services == nil {
+		ss.services = make(map[string]struct{})
+	}
+	ss.services[service] = struct{}{}
+}
+
+// IsService checks whether name is a configured service or a service joined by any client since the server started.
+func (ss *SessionStore) IsService(name string) bool {
+	if _, ok := globals.configs.ServiceMembers[name]; ok {
+		return true
+	}
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	_, ok := ss.services[name]
+	return ok
+}
+
+// ServiceMembers returns the sessions registered under the service name, sorted by ClientID.
+func (ss *SessionStore) ServiceMembers(service string) []*Session {
+	var members []*Session
+	if service == "" {
+		return members
+	}
+	for _, s := range ss.GetClients() {
+		if s.clientInfo.ServiceName == service {
+			members = append(members, s)
+		}
+	}
+	return members
+}
+
+// PickServiceMember chooses the member of the service which receives a request for commandID sent by
+// 'from', using the configured load balancing strategy. Only members accepting commandID which 'from'
+// may send it to are candidates; if the service has members but none of them is a candidate, one of
+// them is returned so that the request is rejected. Returns nil if the service has no member online.
+func (ss *SessionStore) PickServiceMember(service string, routeKey string, from string, commandID int64) *Session {
+	members := ss.ServiceMembers(service)
+	if len(members) == 0 {
+		return nil
+	}
+	candidates := make([]*Session, 0, len(members))
+	for _, s := range members {
+		if s.isCommandAllowed(commandID) && globals.acl.Allowed(from, s.clientInfo.ClientID, commandID) {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		return members[0]
+	}
+
+	switch globals.configs.LoadBalance {
+	case types.LoadBalanceConsistentHash:
+		if routeKey != "" {
+			return pickByHash(candidates, routeKey)
+		}
+	case types.LoadBalanceLeastOutstanding:
+		return pickLeastOutstanding(candidates, ss.nextRoundRobin(service))
+	}
+	return candidates[ss.nextRoundRobin(service)%uint64(len(candidates))]
+}
+
+// nextRoundRobin returns the round-robin counter of the service and increments it.
+func (ss *SessionStore) nextRoundRobin(service string) uint64 {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	if ss.roundRobin == nil {
+		ss.roundRobin = make(map[string]uint64)
+	}
+	n := ss.roundRobin[service]
+	ss.roundRobin[service] = n + 1
+	return n
+}
+
+// pickLeastOutstanding returns the member with the fewest requests waiting for its response.
+// Ties are broken in round-robin order, starting from the member at 'start'.
+func pickLeastOutstanding(members []*Session, start uint64) *Session {
+	var picked *Session
+	var least int
+	for i := range members {
+		s := members[(start+uint64(i))%uint64(len(members))]
+		if n := s.outstandingRequests(); picked == nil || n < least {
+			picked, least = s, n
+		}
+	}
+	return picked
+}
+
+// pickByHash maps the route key to a member by rendezvous hashing, so that requests with the same key
+// keep going to the same member, and only the keys of a member which leaves are moved to other members.
+func pickByHash(members []*Session, routeKey string) *Session {
+	var picked *Session
+	var highest uint64
+	for _, s := range members {
+		h := fnv.New64a()
+		h.Write([]byte(routeKey))
+		h.Write([]byte{0})
+		h.Write([]byte(s.clientInfo.ClientID))
+		if sum := h.Sum64(); picked == nil || sum > highest {
+			picked, highest = s, sum
+		}
+	}
+	return picked
+}
+
+// claimServiceReq assigns to the member clientID the requests stored for the service while it had no
+// member online, and returns them. Requests stored for a client whose ClientID is the service name, and
+// requests the routing access control does not allow to send to clientID, are left alone.
+func claimServiceReq(service string, clientID string) []types.ReqReceived {
+	serviceClaimLock.Lock()
+	defer serviceClaimLock.Unlock()
+
+	items, err := store.MsgObj.GetPendingReq(service)
+	if err != nil {
+		return nil
+	}
+	claimed := items[:0]
+	for _, item := range items {
+		if !item.ToService {
+			continue
+		}
+		//访问控制拒绝发送给该成员的请求留给其他成员
+		if msg := storedMsg(item.Content); msg == nil || msg.Req == nil || !globals.acl.Allowed(msg.Req.From, clientID, msg.Req.CommandID) {
+			continue
+		}
+		item.To = clientID
+		if !updateReqIf(&item, item.Status) {
+			continue
+		}
+		claimed = append(claimed, item)
+	}
+	if len(claimed) > 0 {
+		logger.Info(fmt.Sprintf("[Service] Client [%s] claimed %d requests stored for service [%s]", clientID, len(claimed), service))
+	}
+	return claimed
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"container/list"
 	"encoding/json"
 	"github.com/dato-live/golazy/server/protos"
 	"go.uber.org/zap"
@@ -17,18 +18,42 @@ const (
 	CLUSTER
 )
 
+// 会话关闭原因
+const (
+	CloseReasonLeave       = "leave"
+	CloseReasonStreamError = "stream_error"
+	CloseReasonDuplicate   = "duplicate"
+	CloseReasonShutdown    = "shutdown"
+	CloseReasonIdleTimeout = "idle_timeout"
+	CloseReasonIdentity    = "identity_mismatch"
+	CloseReasonAuth        = "unauthorized"
+	CloseReasonService     = "service_denied"
+)
+
 type ClientInfo struct {
 	ClientID          string
 	ClientName        string
 	ClientVersion     string
 	ClientDescription string
 	AllowedCommandIDs map[int64]string
+	SubscribePresence bool
+	// Logical service the client is a member of, requests sent to the service are balanced across its members
+	ServiceName string
+}
+
+// hasClientInfo checks whether the client has completed Hi.
+func (s *Session) hasClientInfo() bool {
+	return s.clientInfo.ClientID != ""
 }
 
 type MsgSendStatus struct {
 	MsgType string
 	MsgID   string
 	IsOk    bool
+	//发送失败的原因
+	Reason string
+	//确认(Ack)消息的客户端，为空表示该状态来自消息写入结果而非客户端确认
+	AckFrom string
 }
 
 type Session struct {
@@ -41,11 +66,26 @@ type Session struct {
 	// IP address of the client. For long polling this is the IP of the last poll
 	remoteAddr string
 
+	// Names of the verified client certificate, set only for mTLS connections
+	certNames []string
+
 	// Time when the session received any packer from client
 	lastAction time.Time
 
+	// Time when the client connected
+	connectedAt time.Time
+
 	clientInfo ClientInfo
 
+	// Topic patterns the client subscribed to
+	topics map[string]struct{}
+
+	// MsgIDs of the requests delivered to the client and not responded yet, guarded by 'lock'
+	outstanding map[string]struct{}
+
+	// Rate limit of the requests and responses sent on the session, guarded by the rate limiter
+	rateLimit *tokenBucket
+
 	// Outbound mesages, buffered.
 	// The content must be serialized in format suitable for the session.
 	send chan interface{}
@@ -56,13 +96,102 @@ type Session struct {
 	// Content in the same format as for 'send'
 	stop chan interface{}
 
+	// Reason for sending to 'stop', reported when the session is closed
+	stopReason string
+
+	// Closed when the session is closed, ends the read and write loops
+	done chan struct{}
+
+	// Element of SessionStore.lru, used to find idle sessions
+	lpTracker *list.Element
+
 	// Session ID
 	sid string
 
+	// Set once the session has been closed and removed from the store
+	closed bool
+
+	// Set while the messages stored for the client are replayed, guarded by 'lock'
+	replaying bool
+
 	// Needed for long polling and grpc.
 	lock sync.Mutex
 }
 
+// isCommandAllowed checks whether the client declared commandID in its Hi.
+// Clients which declared no commands at all accept none, unless allow_undeclared_commands is set.
+func (s *Session) isCommandAllowed(commandID int64) bool {
+	if len(s.clientInfo.AllowedCommandIDs) == 0 {
+		return globals.configs.AllowUndeclaredCommands
+	}
+	_, ok := s.clientInfo.AllowedCommandIDs[commandID]
+	return ok
+}
+
+// subscribe adds a topic pattern to the subscriptions of the session.
+func (s *Session) subscribe(pattern string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.topics == nil {
+		s.topics = make(map[string]struct{})
+	}
+	s.topics[pattern] = struct{}{}
+}
+
+// unsubscribe removes a topic pattern from the subscriptions of the session.
+func (s *Session) unsubscribe(pattern string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.topics, pattern)
+}
+
+// isSubscribed checks whether any of the topic patterns of the session matches topic.
+func (s *Session) isSubscribed(topic string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for pattern := range s.topics {
+		if topicMatch(pattern, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// setReplaying records whether the messages stored for the client are being replayed.
+func (s *Session) setReplaying(replaying bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.replaying = replaying
+}
+
+// isReplaying checks whether the messages stored for the client are being replayed.
+func (s *Session) isReplaying() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.replaying
+}
+
+// stopSession asks the write loop to send msg to the client and then close the session.
+func (s *Session) stopSession(msg *DMClientMsg, reason string) {
+	if s.stop == nil {
+		return
+	}
+	s.lock.Lock()
+	s.stopReason = reason
+	s.lock.Unlock()
+
+	select {
+	case s.stop <- s.Serialize(msg):
+	default:
+		// Already stopping
+	}
+}
+
 func (s *Session) Serialize(msg *DMClientMsg) interface{} {
 	if s.proto == GRPC {
 		return PbSerialize(msg)
@@ -84,3 +213,18 @@ func (s *Session) queueOut(msg *DMClientMsg) bool {
 	}
 	return true
 }
+
+// queueOutWait is queueOut waiting for room in the send queue until the session is closed, so that
+// messages queued one after another are sent in the same order. Returns false if the session is closed.
+func (s *Session) queueOutWait(msg *DMClientMsg) bool {
+	if s == nil {
+		return true
+	}
+
+	select {
+	case s.send <- s.Serialize(msg):
+	case <-s.done:
+		return false
+	}
+	return true
+}
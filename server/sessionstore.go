@@ -8,10 +8,23 @@ import (
 	"github.com/dato-live/golazy/server/store"
 	"github.com/dato-live/golazy/server/store/types"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/peer"
+	"math"
+	"math/rand"
+	"sort"
 	"sync"
 	"time"
 )
 
+const (
+	// How often to look for idle sessions
+	idleSessionCheckInterval = 5 * time.Second
+	// How long to wait for the Leave message to be sent to an idle session before closing it anyway
+	idleSessionCloseTimeout = 2 * time.Second
+	// Reason recorded for messages the client did not ack in time
+	ackTimeoutReason = "no ack received in time"
+)
+
 // SessionStore holds live sessions. Long polling sessions are stored in a linked list with
 // most recent sessions on top. In addition all sessions are stored in a map indexed by session ID.
 type SessionStore struct {
@@ -25,6 +38,15 @@ type SessionStore struct {
 	uidGen types.UidGenerator
 	// All sessions indexed by session ID
 	sessCache map[string]*Session
+
+	// Running write loops, waited for on shutdown
+	writers sync.WaitGroup
+
+	// Round-robin counters of the services, indexed by service name
+	roundRobin map[string]uint64
+
+	// Names of the services any client joined since the server started
+	services map[string]struct{}
 }
 
 func (ss *SessionStore) NewSession(conn interface{}, sid string) (*Session, int) {
@@ -39,10 +61,16 @@ func (ss *SessionStore) NewSession(conn interface{}, sid string) (*Session, int)
 		s.sid = newSid
 	}
 
+	s.connectedAt = types.TimeNow()
+
 	switch c := conn.(type) {
 	case golazy.Node_MessageLoopServer:
 		s.proto = GRPC
 		s.grpcNode = c
+		if p, ok := peer.FromContext(c.Context()); ok {
+			s.remoteAddr = p.Addr.String()
+			s.certNames = certNames(p)
+		}
 	default:
 		s.proto = NONE
 	}
@@ -51,9 +79,13 @@ func (ss *SessionStore) NewSession(conn interface{}, sid string) (*Session, int)
 		s.send = make(chan interface{}, 1024)
 		s.msgSendStatus = make(chan MsgSendStatus, 4096)
 		s.stop = make(chan interface{}, 1)
+		s.done = make(chan struct{})
 	}
 
+	s.lastAction = types.TimeNow()
+
 	ss.lock.Lock()
+	s.lpTracker = ss.lru.PushFront(&s)
 	ss.sessCache[s.sid] = &s
 	count := len(ss.sessCache)
 	ss.lock.Unlock()
@@ -84,31 +116,172 @@ func (ss *SessionStore) GetByClientID(clientID string) *Session {
 	return nil
 }
 
+// GetClients returns all sessions which have completed Hi, sorted by ClientID.
+func (ss *SessionStore) GetClients() []*Session {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	clients := make([]*Session, 0, len(ss.sessCache))
+	for _, v := range ss.sessCache {
+		if v.hasClientInfo() {
+			clients = append(clients, v)
+		}
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].clientInfo.ClientID < clients[j].clientInfo.ClientID
+	})
+	return clients
+}
+
+// BroadcastPresence notifies every session subscribed to presence, except the session 'about' itself.
+func (ss *SessionStore) BroadcastPresence(about *Session, info *ClientInfo, event string, reason string) {
+	now := types.TimeNow()
+	presence := &DMClientPresence{
+		ClientID:      info.ClientID,
+		ClientName:    info.ClientName,
+		ClientVersion: info.ClientVersion,
+		Event:         event,
+		Reason:        reason,
+		Timestamp:     &now,
+	}
+	for _, s := range ss.GetClients() {
+		if s == about || !s.clientInfo.SubscribePresence {
+			continue
+		}
+		msgID, _ := ss.uidGen.NewMsgUid()
+		s.queueOut(&DMClientMsg{Presence: presence, MsgID: msgID})
+	}
+}
+
+// Publish sends an event to the clients subscribed to its topic, except the publisher.
+// Returns the number of subscribers the event was sent to.
+func (ss *SessionStore) Publish(from *Session, publish *DMPublish) int {
+	count := 0
+	for _, s := range ss.GetClients() {
+		if s == from || !s.isSubscribed(publish.Topic) {
+			continue
+		}
+		msgID, _ := ss.uidGen.NewMsgUid()
+		if s.queueOut(&DMClientMsg{Publish: publish, MsgID: msgID}) {
+			count++
+		}
+	}
+	return count
+}
+
 // Delete removes session from store.
 func (ss *SessionStore) Delete(s *Session) int {
 	ss.lock.Lock()
 	defer ss.lock.Unlock()
 
 	delete(ss.sessCache, s.sid)
+	if s.lpTracker != nil {
+		ss.lru.Remove(s.lpTracker)
+		s.lpTracker = nil
+	}
 	return len(ss.sessCache)
 }
 
-// Shutdown terminates sessionStore. No need to clean up.
-// Don't send to clustered sessions, their servers are not being shut down.
-func (ss *SessionStore) Shutdown() {
+// Touch records activity of the session and moves it to the top of the LRU list.
+func (ss *SessionStore) Touch(s *Session) {
 	ss.lock.Lock()
 	defer ss.lock.Unlock()
 
+	s.lastAction = types.TimeNow()
+	if s.lpTracker != nil {
+		ss.lru.MoveToFront(s.lpTracker)
+	}
+}
+
+// checkIdle returns sessions which have been idle for longer than lifeTime, and sessions
+// which have been idle for longer than half of it and should be pinged.
+func (ss *SessionStore) checkIdle() (expired []*Session, stale []*Session) {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	now := types.TimeNow()
+	expire := now.Add(-ss.lifeTime)
+	ping := now.Add(-ss.lifeTime / 2)
+	for elem := ss.lru.Back(); elem != nil; elem = elem.Prev() {
+		s := elem.Value.(*Session)
+		if s.lastAction.After(ping) {
+			//LRU列表按最后活动时间排序，后续会话均未超时
+			break
+		}
+		if s.lastAction.After(expire) {
+			stale = append(stale, s)
+		} else {
+			expired = append(expired, s)
+		}
+	}
+	return expired, stale
+}
+
+// ExpireIdleSessionLoop periodically closes sessions which have been idle for longer than lifeTime.
+func (ss *SessionStore) ExpireIdleSessionLoop(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(idleSessionCheckInterval):
+			expired, stale := ss.checkIdle()
+			//向空闲会话发送Ping，客户端回复Pong即视为活跃
+			for _, s := range stale {
+				msgID, _ := ss.uidGen.NewMsgUid()
+				now := types.TimeNow()
+				s.queueOut(&DMClientMsg{Ping: &DMPing{Timestamp: &now}, MsgID: msgID})
+			}
+			for _, s := range expired {
+				logger.Warn(fmt.Sprintf("[Session Idle] Session: '%s' (ClientID=%s) has been idle since %s, closing it", s.sid, s.clientInfo.ClientID, s.lastAction))
+				msgID, _ := ss.uidGen.NewMsgUid()
+				now := types.TimeNow()
+				s.stopSession(&DMClientMsg{MsgID: msgID, Leave: &DMClientLeave{ClientID: s.clientInfo.ClientID, Timestamp: &now}}, CloseReasonIdleTimeout)
+				//半开连接可能阻塞在发送上，超时后强制关闭
+				sess := s
+				time.AfterFunc(idleSessionCloseTimeout, func() {
+					sess.closeGrpc(CloseReasonIdleTimeout)
+				})
+			}
+		}
+	}
+}
+
+// Shutdown terminates sessionStore. Every client is sent a Leave, messages queued for it are
+// flushed or marked as failed in the store. Waits for the sessions to close until the deadline.
+// Don't send to clustered sessions, their servers are not being shut down.
+func (ss *SessionStore) Shutdown(deadline time.Time) {
+	ss.lock.Lock()
+	count := len(ss.sessCache)
 	for _, s := range ss.sessCache {
 		msgID, _ := globals.sessionStore.uidGen.NewMsgUid()
 		now := types.TimeNow()
 		shutdown := &DMClientMsg{MsgID: msgID, Leave: &DMClientLeave{ClientID: "", Timestamp: &now}}
-		if s.stop != nil && s.proto != CLUSTER {
-			s.stop <- s.Serialize(shutdown)
+		if s.proto != CLUSTER {
+			s.stopSession(shutdown, CloseReasonShutdown)
 		}
 	}
+	ss.lock.Unlock()
 
-	logger.Info(fmt.Sprintf("SessionStore shut down, sessions terminated: %d", len(ss.sessCache)))
+	done := make(chan struct{})
+	go func() {
+		ss.writers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info(fmt.Sprintf("SessionStore shut down, sessions terminated: %d", count))
+	case <-time.After(time.Until(deadline)):
+		logger.Warn(fmt.Sprintf("SessionStore shut down timed out, sessions terminated: %d, still open: %d", count-ss.Count(), ss.Count()))
+	}
+}
+
+// Count returns the number of live sessions.
+func (ss *SessionStore) Count() int {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	return len(ss.sessCache)
 }
 
 // NewSessionStore initializes a session store.
@@ -125,58 +298,201 @@ func NewSessionStore(lifetime time.Duration) *SessionStore {
 	return ss
 }
 
-//历史失败信息重新发送检查
-func RetrySendMsgLoop() {
+// nextAttemptTime returns when a message which failed to be sent 'retries' times should be
+// retried. The delay grows exponentially from RetryBackoffBaseSecond up to RetryBackoffMaxSecond,
+// half of it is randomized so that messages failed together are not retried all at once.
+func nextAttemptTime(retries int) time.Time {
+	conf := globals.configs
+	delay := float64(conf.RetryBackoffBaseSecond) * math.Pow(conf.RetryBackoffMultiplier, float64(retries))
+	if delay > float64(conf.RetryBackoffMaxSecond) {
+		delay = float64(conf.RetryBackoffMaxSecond)
+	}
+	half := time.Duration(delay * float64(time.Second) / 2)
+	return types.TimeNow().Add(half + time.Duration(rand.Int63n(int64(half)+1)))
+}
+
+//历史失败及超时未确认信息重新发送检查，仅处理已到重传时间的消息
+func RetrySendMsgLoop(stop <-chan struct{}) {
 	for {
 		select {
+		case <-stop:
+			return
 		case <-time.After(time.Second * time.Duration(globals.configs.RetrySecondInterval)):
 			reqItems, _ := store.MsgObj.GetRetryReq()
 			if reqItems != nil {
 				for _, req := range reqItems {
+					if reqExpired(&req) {
+						//请求已超时，请求方不再等待响应，无需重发
+						expireReq(req.MsgID)
+						continue
+					}
+					if req.Status == types.StatusSent {
+						//超时未收到客户端确认，按发送失败处理，到达重传时间后再发送
+						updateMsgStatus(MsgSendStatus{MsgType: "req", MsgID: req.MsgID, Reason: ackTimeoutReason})
+						continue
+					}
 					reqToSess := globals.sessionStore.GetByClientID(req.To)
-					if reqToSess != nil {
-						var msg DMClientMsg
-						err := json.Unmarshal([]byte(req.Content), &msg)
-						if err != nil {
-							logger.Error("RetrySendMsgLoop Unmarshal Req failed", zap.Error(err))
-						} else {
-							reqToSess.queueOut(&msg)
-						}
-						req.Retries += 1
-						req.Status = types.StatusRetry
-
-					} else {
-						req.Retries += 1
-						req.Status = types.StatusFailed
+					if reqToSess == nil || reqToSess.isReplaying() {
+						//目标离线，待目标上线后再发送；目标正在重放存储的消息时由重放按顺序发送
+						continue
 					}
-
-					store.MsgObj.UpdateReq(&req)
+					msg := storedMsg(req.Content)
+					if msg == nil || msg.Req == nil {
+						continue
+					}
+					if !reqToSess.isCommandAllowed(msg.Req.CommandID) {
+						//目标重新上线后可能不再接受该命令，不再重发
+						rejectStoredReq(&req, msg.Req.CommandID)
+						continue
+					}
+					req.Retries += 1
+					req.Status = types.StatusRetry
+					if !updateReqIf(&req, types.StatusFailed) {
+						//读取后已被确认或已处理
+						continue
+					}
+					globals.reqTracker.Redeliver(&req)
+					reqToSess.deliver(msg)
 				}
 			}
 
 			respItems, _ := store.MsgObj.GetRetryResp()
 			if respItems != nil {
 				for _, resp := range respItems {
+					if resp.Status == types.StatusSent {
+						//超时未收到客户端确认，按发送失败处理，到达重传时间后再发送
+						updateMsgStatus(MsgSendStatus{MsgType: "resp", MsgID: resp.MsgID, Reason: ackTimeoutReason})
+						continue
+					}
 					respToSess := globals.sessionStore.GetByClientID(resp.To)
-					if respToSess != nil {
-						var msg DMClientMsg
-						err := json.Unmarshal([]byte(resp.Content), &msg)
-						if err != nil {
-							logger.Error("RetrySendMsgLoop Unmarshal Resp failed", zap.Error(err))
-						} else {
-							respToSess.queueOut(&msg)
-						}
-						resp.Retries += 1
-						resp.Status = types.StatusRetry
-
-					} else {
-						resp.Retries += 1
-						resp.Status = types.StatusFailed
+					if respToSess == nil || respToSess.isReplaying() {
+						//目标离线，待目标上线后再发送；目标正在重放存储的消息时由重放按顺序发送
+						continue
 					}
-
-					store.MsgObj.UpdateResp(&resp)
+					msg := storedMsg(resp.Content)
+					if msg == nil {
+						continue
+					}
+					resp.Retries += 1
+					resp.Status = types.StatusRetry
+					if !updateRespIf(&resp, types.StatusFailed) {
+						continue
+					}
+					respToSess.deliver(msg)
 				}
 			}
 		}
 	}
 }
+
+// ReplayPendingMsg sends to a client which has just completed Hi the messages stored for it
+// while it was offline, which failed to be delivered or which it did not ack, in the order they were received.
+// It waits for room in the send queue rather than dropping messages, which would change their order.
+// RetrySendMsgLoop skips the client until the replay is done.
+func ReplayPendingMsg(sess *Session) {
+	defer sess.setReplaying(false)
+
+	clientID := sess.clientInfo.ClientID
+	reqItems, err := store.MsgObj.GetPendingReq(clientID)
+	if err != nil {
+		return
+	}
+	respItems, err := store.MsgObj.GetPendingResp(clientID)
+	if err != nil {
+		return
+	}
+	//发送给所属服务且服务无成员在线时存储的请求，由当前成员接收
+	if service := sess.clientInfo.ServiceName; service != "" && service != clientID {
+		reqItems = append(reqItems, claimServiceReq(service, clientID)...)
+		sort.Slice(reqItems, func(i, j int) bool {
+			return types.ParseMsgUid(reqItems[i].MsgID) < types.ParseMsgUid(reqItems[j].MsgID)
+		})
+	}
+	if len(reqItems)+len(respItems) > 0 {
+		logger.Info(fmt.Sprintf("[Replay] Sending %d requests and %d responses stored for client [%s]", len(reqItems), len(respItems), clientID))
+	}
+
+	//按消息ID(生成顺序)合并请求与响应
+	i, j := 0, 0
+	for i < len(reqItems) || j < len(respItems) {
+		if j >= len(respItems) || (i < len(reqItems) && types.ParseMsgUid(reqItems[i].MsgID) < types.ParseMsgUid(respItems[j].MsgID)) {
+			req := &reqItems[i]
+			i++
+			if reqExpired(req) {
+				//请求已超时，请求方不再等待响应，无需发送
+				expireReq(req.MsgID)
+				continue
+			}
+			msg := storedMsg(req.Content)
+			if msg == nil || msg.Req == nil {
+				continue
+			}
+			if !sess.isCommandAllowed(msg.Req.CommandID) {
+				//客户端本次上线未声明该命令，不再发送
+				rejectStoredReq(req, msg.Req.CommandID)
+				continue
+			}
+			status := req.Status
+			if req.Status == types.StatusPending {
+				req.Status = types.StatusQueued
+			} else {
+				//发送失败或已发送但未确认的消息
+				req.Retries += 1
+				req.Status = types.StatusRetry
+			}
+			if !updateReqIf(req, status) {
+				//读取后已被确认或已处理
+				continue
+			}
+			globals.reqTracker.Redeliver(req)
+			if !sess.deliverWait(msg) {
+				//会话已关闭，其余消息待客户端下次上线后发送
+				return
+			}
+		} else {
+			resp := &respItems[j]
+			j++
+			msg := storedMsg(resp.Content)
+			if msg == nil {
+				continue
+			}
+			status := resp.Status
+			if resp.Status == types.StatusPending {
+				resp.Status = types.StatusQueued
+			} else {
+				//发送失败或已发送但未确认的消息
+				resp.Retries += 1
+				resp.Status = types.StatusRetry
+			}
+			if !updateRespIf(resp, status) {
+				continue
+			}
+			if !sess.deliverWait(msg) {
+				return
+			}
+		}
+	}
+}
+
+// storedMsg decodes a message stored as JSON. Returns nil if the content is invalid.
+func storedMsg(content string) *DMClientMsg {
+	var msg DMClientMsg
+	err := json.Unmarshal([]byte(content), &msg)
+	if err != nil {
+		logger.Error("storedMsg Unmarshal failed", zap.Error(err))
+		return nil
+	}
+	return &msg
+}
+
+// rejectStoredReq moves a stored request whose target does not accept its command to the dead letters,
+// it would be rejected again on every retry.
+func rejectStoredReq(req *types.ReqReceived, commandID int64) {
+	status := req.Status
+	req.Status = types.StatusDead
+	req.LastError = fmt.Sprintf("target [%s] does not accept command [%d]", req.To, commandID)
+	if !updateReqIf(req, status) {
+		return
+	}
+	logger.Warn(fmt.Sprintf("[Dead Letter] Request [%s] from [%s] to [%s] rejected: %s", req.MsgID, req.From, req.To, req.LastError))
+}
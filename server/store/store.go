@@ -2,20 +2,33 @@ package store
 
 import (
 	"errors"
+	"fmt"
 	"github.com/dato-live/golazy/server/adapter"
 	"github.com/dato-live/golazy/server/config"
+	"github.com/dato-live/golazy/server/logs"
 	t "github.com/dato-live/golazy/server/store/types"
+	"go.uber.org/zap"
+	"sort"
 	"time"
 )
 
+// 当前使用的数据库适配器，由配置文件 adapter_type 选择
 var adp adapter.Adapter
+
+// 所有已注册的数据库适配器，按名称索引
+var adapters map[string]adapter.Adapter
 var uGen t.UidGenerator
 var configs config.Config
 
 func openAdapter(conf config.Config) error {
 	configs = conf
 	if adp == nil {
-		return errors.New("store: database adapter is missing")
+		name := conf.Store.Adapters.AdapterType
+		a, ok := adapters[name]
+		if !ok {
+			return fmt.Errorf("store: unknown adapter '%s', registered adapters: %v", name, AdapterNames())
+		}
+		adp = a
 	}
 
 	if adp.IsOpen() {
@@ -31,8 +44,7 @@ func openAdapter(conf config.Config) error {
 }
 
 // Open initializes the persistence system. Adapter holds a connection pool for a database instance.
-// 	 name - name of the adapter rquested in the config file
-//   jsonconf - configuration string
+// The adapter is selected by conf.Store.Adapters.AdapterType.
 func Open(conf config.Config) error {
 	if err := openAdapter(conf); err != nil {
 		return err
@@ -43,7 +55,7 @@ func Open(conf config.Config) error {
 
 // Close terminates connection to persistent storage.
 func Close() error {
-	if adp.IsOpen() {
+	if adp != nil && adp.IsOpen() {
 		return adp.Close()
 	}
 
@@ -80,18 +92,32 @@ func InitDb(conf config.Config, reset bool) error {
 	return adp.CreateDb(reset)
 }
 
-// RegisterAdapter makes a persistence adapter available.
-// If Register is called twice or if the adapter is nil, it panics.
+// RegisterAdapter makes a persistence adapter available under the given name.
+// If Register is called twice with the same name or if the adapter is nil, it panics.
 func RegisterAdapter(name string, a adapter.Adapter) {
 	if a == nil {
 		panic("store: Register adapter is nil")
 	}
 
-	if adp != nil {
-		panic("store: adapter '" + adp.GetName() + "' is already registered")
+	if adapters == nil {
+		adapters = make(map[string]adapter.Adapter)
 	}
 
-	adp = a
+	if _, dup := adapters[name]; dup {
+		panic("store: adapter '" + name + "' is already registered")
+	}
+
+	adapters[name] = a
+}
+
+// AdapterNames returns the sorted names of all registered adapters.
+func AdapterNames() []string {
+	names := make([]string, 0, len(adapters))
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 type MsgObjMapper struct {
@@ -115,6 +141,10 @@ func (MsgObjMapper) GetRespByMsgID(msgId string) (*t.RespReceived, error) {
 	return adp.GetRespByMsgID(msgId)
 }
 
+func (MsgObjMapper) GetReqByReqID(from string, to string, reqId string) (*t.ReqReceived, error) {
+	return adp.GetReqByReqID(from, to, reqId)
+}
+
 func (MsgObjMapper) UpdateReq(req *t.ReqReceived) error {
 	return adp.UpdateReq(req)
 }
@@ -123,6 +153,14 @@ func (MsgObjMapper) UpdateResp(resp *t.RespReceived) error {
 	return adp.UpdateResp(resp)
 }
 
+func (MsgObjMapper) UpdateReqIf(req *t.ReqReceived, status string) (bool, error) {
+	return adp.UpdateReqIf(req, status)
+}
+
+func (MsgObjMapper) UpdateRespIf(resp *t.RespReceived, status string) (bool, error) {
+	return adp.UpdateRespIf(resp, status)
+}
+
 func (MsgObjMapper) DeleteReq(id int64) error {
 	return adp.DeleteReq(id)
 }
@@ -131,8 +169,8 @@ func (MsgObjMapper) DeleteResp(id int64) error {
 	return adp.DeleteResp(id)
 }
 
-func (MsgObjMapper) DeleteSendedOrExpireMsg() error {
-	return adp.DeleteSendedOrExpireMsg()
+func (MsgObjMapper) DeleteSendedOrExpireMsg(policy t.RetentionPolicy) (int64, int64, error) {
+	return adp.DeleteSendedOrExpireMsg(policy)
 }
 
 func (MsgObjMapper) GetRetryReq() ([]t.ReqReceived, error) {
@@ -143,11 +181,42 @@ func (MsgObjMapper) GetRetryResp() ([]t.RespReceived, error) {
 	return adp.GetRetryResp()
 }
 
-func DbClearLoop() {
+func (MsgObjMapper) GetPendingReq(to string) ([]t.ReqReceived, error) {
+	return adp.GetPendingReq(to)
+}
+
+func (MsgObjMapper) GetPendingResp(to string) ([]t.RespReceived, error) {
+	return adp.GetPendingResp(to)
+}
+
+func (MsgObjMapper) GetDeadReq(filter t.DeadLetterFilter) ([]t.ReqReceived, error) {
+	return adp.GetDeadReq(filter)
+}
+
+func (MsgObjMapper) GetDeadResp(filter t.DeadLetterFilter) ([]t.RespReceived, error) {
+	return adp.GetDeadResp(filter)
+}
+
+// retentionPolicy returns the retention of the stored messages set in the configuration.
+func retentionPolicy() t.RetentionPolicy {
+	return t.RetentionPolicy{
+		Succeeded: time.Minute * time.Duration(configs.RetentionSucceededMinute),
+		Failed:    time.Minute * time.Duration(configs.RetentionFailedMinute),
+		Dead:      time.Minute * time.Duration(configs.RetentionDeadMinute),
+		BatchSize: configs.CleanDbBatchSize,
+	}
+}
+
+func DbClearLoop(stop <-chan struct{}) {
 	for {
 		select {
+		case <-stop:
+			return
 		case <-time.After(time.Minute * time.Duration(configs.CleanDbMinuteInterval)):
-			MsgObj.DeleteSendedOrExpireMsg()
+			reqCount, respCount, err := MsgObj.DeleteSendedOrExpireMsg(retentionPolicy())
+			if reqCount+respCount > 0 || err != nil {
+				logs.GetLogger().Info(fmt.Sprintf("[Clean Db] Purged %d requests and %d responses", reqCount, respCount), zap.Error(err))
+			}
 		}
 	}
 }
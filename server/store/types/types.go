@@ -14,19 +14,80 @@ func GetExpiresTime(minute int) time.Time {
 }
 
 const DefaultMsgVersion = "v1.0"
+const DefaultAdapterType = "mysql"
 const DefaultMaxRetryCount = 100
 const DefaultRetrySecondInterval = 30
+
+// 请求发送到服务(ServiceName)时选择服务成员的负载均衡策略
+const LoadBalanceRoundRobin = "round_robin"
+const LoadBalanceLeastOutstanding = "least_outstanding"
+const LoadBalanceConsistentHash = "consistent_hash"
+const DefaultLoadBalance = LoadBalanceRoundRobin
+
+// 路由访问控制(ACL)未匹配任何规则时的处理方式
+const ACLAllow = "allow"
+const ACLDeny = "deny"
+const DefaultACLDefault = ACLAllow
+
+// 失败消息重传的指数退避参数：首次等待时间、最长等待时间及倍数
+const DefaultRetryBackoffBaseSecond = 30
+const DefaultRetryBackoffMaxSecond = 3600
+const DefaultRetryBackoffMultiplier = 2.0
 const DefaultCleanDbMinuteInterval = 15
 
+// 启用TLS时默认的最低TLS版本
+const DefaultTLSMinVersion = "1.2"
+
+// 客户端认证时允许的时钟偏差
+const DefaultMaxClockSkewSecond = 300
+
+// 清理数据库时每次删除的最大行数，避免长时间锁表
+const DefaultCleanDbBatchSize = 1000
+
+// 已完成的消息在数据库中的默认保留时间，期间仍可识别重复的响应
+const DefaultRetentionSucceededMinute = 60
+
 // 会话Session超时时间间隔
 const DefaultIdleSessionTimeoutSecond = 55
 const DefaultMaxMessageSize = 20971520
 const DefaultMessageExpireMinuteInterval = 600
+
+// 同步调用(Call)未设置超时时间时的默认超时时间
+const DefaultCallTimeoutSecond = 30
+
+// 广播请求等待各目标响应的默认时间
+const DefaultBroadcastTimeoutSecond = 10
+
+// 服务器关闭时等待会话结束的最长时间
+const DefaultShutdownTimeoutSecond = 10
+
+// 消息发送后等待客户端确认(Ack)的时间，超时未确认的消息将重传
+const DefaultAckTimeoutSecond = 30
 const StatusQueued = "Queued"
+
+// 消息已写入客户端连接，等待客户端确认(Ack)
+const StatusSent = "Sent"
+
+// 客户端已确认收到消息，对请求而言目标尚未响应
+const StatusAcked = "Acked"
+
+// 请求已收到目标的响应，不再发送，重复的响应将被拒绝
+const StatusResponded = "Responded"
+
+// 旧版本中消息写入连接即视为发送成功，保留以便清理历史记录
 const StatusSucceeded = "Succeeded"
 const StatusFailed = "Failed"
 const StatusRetry = "Retrying"
 
+// 目标客户端离线，消息已存储，待目标上线后发送
+const StatusPending = "Pending"
+
+// 请求超过截止时间仍未收到响应，已向请求方发送超时响应，不再重传
+const StatusTimeout = "Timeout"
+
+// 重传次数超过上限的死信消息，不再自动重传，保留至人工重新投递或清除
+const StatusDead = "Dead"
+
 //键值型元数据记录表
 type KvMeta struct {
 	KeyName  string `xorm:"varchar(32) notnull unique index pk 'key_name'"`
@@ -45,6 +106,14 @@ type ReqReceived struct {
 	ExpiresAt time.Time `xorm:"datetime 'expires_at'"`
 	Retries   int       `xorm:"'retries'"`
 	Status    string    `xorm:"varchar(32) index 'status'"`
+	//下次重传时间，为空表示可立即重传
+	NextAttemptAt time.Time `xorm:"datetime index 'next_attempt_at'"`
+	//最近一次发送失败的原因
+	LastError string `xorm:"varchar(255) 'last_error'"`
+	//等待响应的截止时间，为空表示不限制，超时后不再重传
+	Deadline time.Time `xorm:"datetime index 'deadline'"`
+	//请求发送到服务而非客户端，服务无成员在线时存储的请求仅由服务成员领取
+	ToService bool `xorm:"'to_service'"`
 }
 
 type RespReceived struct {
@@ -59,4 +128,37 @@ type RespReceived struct {
 	ExpiresAt time.Time `xorm:"datetime 'expires_at'"`
 	Retries   int       `xorm:"'retries'"`
 	Status    string    `xorm:"varchar(32) index 'status'"`
+	//下次重传时间，为空表示可立即重传
+	NextAttemptAt time.Time `xorm:"datetime index 'next_attempt_at'"`
+	//最近一次发送失败的原因
+	LastError string `xorm:"varchar(255) 'last_error'"`
+	//响应对应的请求消息ID，及从发送请求到收到响应的耗时(毫秒)，服务端生成的响应为空
+	ReqMsgID  string `xorm:"varchar(128) 'req_msg_id'"`
+	LatencyMs int64  `xorm:"'latency_ms'"`
+}
+
+// RetentionPolicy 已不再发送的消息在数据库中的保留时间，超过保留时间的消息由定时清理删除
+type RetentionPolicy struct {
+	//已响应(Responded)的请求及已送达(Acked)的响应
+	Succeeded time.Duration
+	//超时未响应(Timeout)及过期仍未送达的消息
+	Failed time.Duration
+	//死信(Dead)，为0表示不自动清理，仅可通过管理接口删除
+	Dead time.Duration
+	//每次删除的最大行数
+	BatchSize int
+}
+
+// DeadLetterFilter 死信消息查询条件，值为空的条件不参与过滤
+type DeadLetterFilter struct {
+	MsgID string
+	//消息的发送方或接收方
+	ClientID string
+	//请求ID，对响应消息为所响应的请求ID
+	ReqID string
+	//消息接收时间范围 [Since, Until)
+	Since time.Time
+	Until time.Time
+	//返回的最大记录数，0表示不限制
+	Limit int
 }
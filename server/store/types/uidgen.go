@@ -6,6 +6,7 @@ import (
 	"github.com/sony/sonyflake"
 	"log"
 	"strconv"
+	"strings"
 )
 
 type UidGenerator struct {
@@ -45,3 +46,13 @@ func (ug *UidGenerator) NewMsgUid() (string, error) {
 	idStr := fmt.Sprintf("msg-%s", id)
 	return idStr, nil
 }
+
+// ParseMsgUid returns the numeric part of a message uid. Uids are generated in increasing order,
+// so it can be used to sort messages by the time they were created. Returns 0 for malformed uids.
+func ParseMsgUid(msgID string) uint64 {
+	id, err := strconv.ParseUint(strings.TrimPrefix(msgID, "msg-"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/dato-live/golazy/server/config"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"io/ioutil"
+)
+
+// 配置中min_version可选的TLS版本
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// serverTLSConfig builds the TLS configuration of the gRPC listener, nil if TLS is not enabled.
+// Client certificates signed by the client CA are required if one is set.
+func serverTLSConfig(conf config.TLSConfig) (*tls.Config, error) {
+	if conf.CertFile == "" {
+		return nil, nil
+	}
+	minVersion, ok := tlsVersions[conf.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("tls: unknown min_version '%s'", conf.MinVersion)
+	}
+	cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("tls: load server certificate failed: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   minVersion,
+	}
+	if conf.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(conf.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: read client CA failed: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificate found in client CA file '%s'", conf.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// certNames returns the Common Name and the DNS names of the verified client certificate of the
+// connection, nil if the client did not present one.
+func certNames(p *peer.Peer) []string {
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	cert := info.State.VerifiedChains[0][0]
+	var names []string
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return append(names, cert.DNSNames...)
+}
+
+// certMatches checks whether the client certificate of the session was issued to clientID.
+func (sess *Session) certMatches(clientID string) bool {
+	return nameMatches(sess.certNames, clientID)
+}
+
+// nameMatches checks whether clientID is one of the names of a client certificate.
+func nameMatches(names []string, clientID string) bool {
+	for _, name := range names {
+		if name == clientID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+)
+
+// 主题名以 '.' 分隔为多级，如 config.service.updated
+// 订阅时可使用通配符：'*' 匹配任意一级，'>' 仅能位于末尾，匹配剩余的一级或多级
+const (
+	topicSeparator      = "."
+	topicWildcardOne    = "*"
+	topicWildcardRemain = ">"
+)
+
+// validTopic checks the topic name. Wildcards are accepted only if isPattern is true,
+// that is when subscribing.
+func validTopic(topic string, isPattern bool) bool {
+	if topic == "" {
+		return false
+	}
+	levels := strings.Split(topic, topicSeparator)
+	for i, level := range levels {
+		switch {
+		case level == "":
+			return false
+		case level == topicWildcardOne:
+			if !isPattern {
+				return false
+			}
+		case level == topicWildcardRemain:
+			if !isPattern || i != len(levels)-1 {
+				return false
+			}
+		case strings.ContainsAny(level, topicWildcardOne+topicWildcardRemain):
+			return false
+		}
+	}
+	return true
+}
+
+// topicMatch checks if the topic matches the subscription pattern.
+func topicMatch(pattern, topic string) bool {
+	pl := strings.Split(pattern, topicSeparator)
+	tl := strings.Split(topic, topicSeparator)
+	for i, level := range pl {
+		if level == topicWildcardRemain {
+			return len(tl) > i
+		}
+		if i >= len(tl) || (level != topicWildcardOne && level != tl[i]) {
+			return false
+		}
+	}
+	return len(pl) == len(tl)
+}